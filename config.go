@@ -1,21 +1,97 @@
 package qlog
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FortuneW/qlog/internal"
+)
 
 // Config 日志配置
 type Config struct {
-	ServiceName   string // 服务名
-	ServerLogDir  string // 服务日志目录
-	ManagerLogDir string // 管理日志目录
-	MaxBackups    int    // 最大备份数量
-	MaxSize       int    // 单个日志文件最大尺寸(MB)
-	KeepDays      int    // 日志保留天数
-	Level         string // 日志级别 (DEB/INF/WAR/ERR/OFF)
-	Compress      bool   // 是否压缩
-	Rotation      string // 轮转方式 ("size"/"time")
-	Mode          string // 日志模式 ("file"/"console")
-	ToConsole     bool   // 是否输出到控制台,即使file模式
-	ColorConsole  bool   // 仅console有效
+	ServiceName   string        // 服务名
+	ServerLogDir  string        // 服务日志目录
+	ManagerLogDir string        // 管理日志目录
+	MaxBackups    int           // 最大备份数量
+	MaxSize       int           // 单个日志文件最大尺寸(MB)
+	KeepDays      int           // 日志保留天数
+	MaxAge        time.Duration // 日志保留时长,按文件修改时间判断,优先于KeepDays
+	MaxTotalSize  int           // 单个日志流(备份+当前文件)占用上限(MB),0表示不限制
+	DiskThreshold int           // 归档文件占用磁盘容量百分比阈值(1-100),0表示默认80
+	Level         string        // 日志级别 (DEB/INF/WAR/ERR/PNC/FTL/OFF)
+	Compress      bool          // 是否压缩
+	Rotation      string        // 轮转方式 ("size"/"time")
+	Mode          string        // 日志模式 ("file"/"console")
+	ToConsole     bool          // 是否输出到控制台,即使file模式
+	ConsoleLevel  string        // ToConsole时控制台的最低级别,默认与Level相同
+	ColorConsole  bool          // 仅console有效
+
+	RetentionDryRun bool // 保留策略演练模式,只打印删除决策而不删除文件
+	HandleSignals   bool // 监听信号,SIGHUP重新打开日志文件,SIGUSR1轮转日志文件
+
+	FallbackDir        string // 主日志目录故障时的备用目录
+	FallbackStderr     bool   // 主日志目录故障时输出到标准错误
+	FallbackBufferSize int    // 故障期间内存缓存的日志条数,恢复后回放到主日志文件
+
+	CompressConcurrency int   // 压缩并发数,所有日志文件共享,0表示默认1
+	CompressRateLimit   int64 // 压缩读取限速(字节/秒),0表示不限速
+
+	DiskHighWatermark int    // 磁盘使用率高水位(%),超过后进入保护模式,0表示不开启
+	DiskLowWatermark  int    // 磁盘使用率低水位(%),低于后退出保护模式,0表示与高水位相同
+	DiskPressureLevel string // 保护模式下提升到的日志级别,默认INF
+
+	RecentRecords int    // 内存中保留最近日志的条数,与RecentBytes都为0时不保留
+	RecentBytes   int    // 内存中保留最近日志的字节数
+	RecentLevel   string // 写入最近日志的最低级别,不受Level限制,默认TRA
+
+	TraceBufferRecords int           // 每个链路缓存低于Level的日志条数,出现ERR时写入文件,0表示不缓存
+	TraceBufferTraces  int           // 同时缓存的链路数上限,0表示默认1000
+	TraceBufferTimeout time.Duration // 链路未结束时的最长缓存时间,0表示默认1分钟
+
+	StackLevel string // 附加调用栈和错误原因链的最低级别(如ERR),默认不附加,单次调用可传入WithStack()
+
+	CrashLog bool // 进程崩溃时运行时输出的错误和所有协程调用栈写入<ServiceName>_crash.log,仅file模式,不刷新未落盘的日志
+
+	Server  StreamConfig // 服务日志单独的轮转和保留配置,未配置的项使用上面的全局配置
+	Manager StreamConfig // 管理日志单独的轮转和保留配置,未配置的项使用上面的全局配置
+
+	ErrorLog       bool         // 将server.log中WAR及以上的日志同时写入<ServiceName>_error.log,仅file模式
+	ErrorLogStream StreamConfig // 错误日志单独的轮转和保留配置,如更长的MaxAge,未配置的项使用全局配置
+
+	Redact         bool     // 开启内置脱敏:银行卡号(Luhn校验)、邮箱、手机号、Bearer令牌、password等键值
+	RedactPatterns []string // 自定义脱敏正则,匹配的内容替换为******
+	RedactFields   []string // 脱敏的字段名,钩子添加的字段和消息中同名key=value的值替换为******
+
+	MaxContentLength uint32 // 单条日志内容的最大字节数,超过时按字符截断并追加...(truncated N bytes),0表示不限制
+
+	Sanitize  string // 消息中换行和控制字符的处理("escape"/"multiline"/"off"),默认escape防止伪造日志行
+	StripAnsi bool   // 删除消息中的ANSI转义序列,默认转义
+
+	Routes []RouteRule // 按模块和级别分流到单独文件的规则,按顺序匹配第一条,仅file模式
+
+	StreamDir string                  // GetStream命名日志流的目录,默认与ServerLogDir相同
+	Streams   map[string]StreamConfig // 命名日志流单独的轮转和保留配置,键为日志流名称
+}
+
+// StreamConfig 单个日志流的轮转和保留配置,零值表示使用全局配置
+type StreamConfig struct {
+	Rotation     string        // 轮转方式 ("size"/"time")
+	MaxSize      int           // 单个日志文件最大尺寸(MB)
+	MaxBackups   int           // 最大备份数量
+	KeepDays     int           // 日志保留天数
+	MaxAge       time.Duration // 日志保留时长,按文件修改时间判断
+	MaxTotalSize int           // 日志流(备份+当前文件)占用上限(MB)
+	Compress     *bool         // 是否压缩,nil表示使用全局配置
+}
+
+// RouteRule 分流规则,匹配的日志写入<ServiceName>_<Name>.log,不再写入server.log
+type RouteRule struct {
+	Name         string   // 文件名中的名称
+	Modules      []string // 匹配的模块名(GetRLog的参数),为空时匹配所有模块
+	Levels       []string // 匹配的日志级别,为空时匹配所有级别
+	StreamConfig          // 该文件单独的轮转和保留配置
 }
 
 const (
@@ -27,8 +103,11 @@ const (
 	minBackups = 1   // 最小备份数量
 	maxBackups = 100 // 最大备份数量
 
+	// 压缩并发数限制
+	maxCompressConcurrency = 64
+
 	// 合法的日志级别
-	validLogLevels = "DEB,INF,WAR,ERR,OFF"
+	validLogLevels = "TRA,DEB,INF,WAR,ERR,PNC,FTL,OFF"
 
 	// 合法的轮转方式
 	rotationSize = "size"
@@ -37,8 +116,21 @@ const (
 	// 合法的日志模式
 	modeFile    = "file"
 	modeConsole = "console"
+
+	// 合法的消息处理方式
+	sanitizeEscape    = "escape"
+	sanitizeMultiline = "multiline"
+	sanitizeOff       = "off"
 )
 
+// reservedStreamNames 内置日志文件使用的名称,分流规则和命名日志流不能使用
+var reservedStreamNames = map[string]struct{}{
+	"server":  {},
+	"manager": {},
+	"crash":   {},
+	"error":   {},
+}
+
 // ValidateConfig 验证日志配置是否合法
 func (c *Config) ValidateConfig() error {
 	// 验证日志目录
@@ -63,6 +155,136 @@ func (c *Config) ValidateConfig() error {
 			c.MaxBackups, minBackups, maxBackups)
 	}
 
+	// 验证保留策略
+	if c.KeepDays < 0 {
+		return fmt.Errorf("invalid keep days: %d, should not be negative", c.KeepDays)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("invalid max age: %v, should not be negative", c.MaxAge)
+	}
+	if c.MaxTotalSize < 0 {
+		return fmt.Errorf("invalid max total size: %d, should not be negative", c.MaxTotalSize)
+	}
+	if c.DiskThreshold < 0 || c.DiskThreshold > 100 {
+		return fmt.Errorf("invalid disk threshold: %d, should be between 0 and 100", c.DiskThreshold)
+	}
+
+	// 验证备用输出
+	if c.FallbackBufferSize < 0 {
+		return fmt.Errorf("invalid fallback buffer size: %d, should not be negative", c.FallbackBufferSize)
+	}
+	if len(c.FallbackDir) > 0 && (c.FallbackDir == c.ServerLogDir || c.FallbackDir == c.ManagerLogDir) {
+		return fmt.Errorf("fallback directory should differ from log directories: %s", c.FallbackDir)
+	}
+
+	// 验证压缩参数
+	if c.CompressConcurrency < 0 || c.CompressConcurrency > maxCompressConcurrency {
+		return fmt.Errorf("invalid compress concurrency: %d, should be between 0 and %d",
+			c.CompressConcurrency, maxCompressConcurrency)
+	}
+	if c.CompressRateLimit < 0 {
+		return fmt.Errorf("invalid compress rate limit: %d, should not be negative", c.CompressRateLimit)
+	}
+
+	// 验证磁盘压力保护
+	if c.DiskHighWatermark < 0 || c.DiskHighWatermark > 100 {
+		return fmt.Errorf("invalid disk high watermark: %d, should be between 0 and 100", c.DiskHighWatermark)
+	}
+	if c.DiskLowWatermark < 0 || (c.DiskHighWatermark > 0 && c.DiskLowWatermark > c.DiskHighWatermark) {
+		return fmt.Errorf("invalid disk low watermark: %d, should be between 0 and high watermark %d",
+			c.DiskLowWatermark, c.DiskHighWatermark)
+	}
+	if len(c.DiskPressureLevel) > 0 {
+		if err := CheckLogLevelStr(c.DiskPressureLevel); err != nil {
+			return fmt.Errorf("invalid disk pressure level: %s, valid levels are: %s",
+				c.DiskPressureLevel, validLogLevels)
+		}
+	}
+
+	// 验证最近日志缓冲区
+	if c.RecentRecords < 0 || c.RecentBytes < 0 {
+		return fmt.Errorf("invalid recent records: %d or bytes: %d, should not be negative",
+			c.RecentRecords, c.RecentBytes)
+	}
+	if len(c.RecentLevel) > 0 {
+		if err := CheckLogLevelStr(c.RecentLevel); err != nil {
+			return fmt.Errorf("invalid recent level: %s, valid levels are: %s",
+				c.RecentLevel, validLogLevels)
+		}
+	}
+
+	// 验证链路缓存
+	if c.TraceBufferRecords < 0 || c.TraceBufferTraces < 0 || c.TraceBufferTimeout < 0 {
+		return fmt.Errorf("invalid trace buffer records: %d, traces: %d or timeout: %v, should not be negative",
+			c.TraceBufferRecords, c.TraceBufferTraces, c.TraceBufferTimeout)
+	}
+
+	// 验证控制台级别
+	if len(c.ConsoleLevel) > 0 {
+		if err := CheckLogLevelStr(c.ConsoleLevel); err != nil {
+			return fmt.Errorf("invalid console level: %s, valid levels are: %s",
+				c.ConsoleLevel, validLogLevels)
+		}
+	}
+
+	// 验证调用栈级别
+	if len(c.StackLevel) > 0 {
+		if err := CheckLogLevelStr(c.StackLevel); err != nil {
+			return fmt.Errorf("invalid stack level: %s, valid levels are: %s",
+				c.StackLevel, validLogLevels)
+		}
+	}
+
+	// 验证服务日志和管理日志单独的配置
+	if err := c.Server.validate(); err != nil {
+		return fmt.Errorf("invalid server stream: %w", err)
+	}
+	if err := c.Manager.validate(); err != nil {
+		return fmt.Errorf("invalid manager stream: %w", err)
+	}
+	if err := c.ErrorLogStream.validate(); err != nil {
+		return fmt.Errorf("invalid error log stream: %w", err)
+	}
+
+	// 验证消息处理方式
+	if len(c.Sanitize) > 0 && c.Sanitize != sanitizeEscape && c.Sanitize != sanitizeMultiline && c.Sanitize != sanitizeOff {
+		return fmt.Errorf("invalid sanitize: %s, should be one of '%s', '%s' or '%s'",
+			c.Sanitize, sanitizeEscape, sanitizeMultiline, sanitizeOff)
+	}
+
+	// 验证脱敏正则
+	for _, pattern := range c.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redact pattern: %s, error: %w", pattern, err)
+		}
+	}
+
+	// 验证分流规则
+	names := make(map[string]struct{}, len(c.Routes))
+	for _, route := range c.Routes {
+		if err := route.validate(); err != nil {
+			return err
+		}
+		if _, ok := names[route.Name]; ok {
+			return fmt.Errorf("duplicate route name: %s", route.Name)
+		}
+		names[route.Name] = struct{}{}
+	}
+
+	// 验证命名日志流，与分流规则在同一目录时不能重名
+	sameDir := len(c.StreamDir) == 0 || c.StreamDir == c.ServerLogDir
+	for name, stream := range c.Streams {
+		if err := validateStreamName(name); err != nil {
+			return err
+		}
+		if _, ok := names[name]; ok && sameDir {
+			return fmt.Errorf("stream name conflicts with route name: %s", name)
+		}
+		if err := stream.validate(); err != nil {
+			return fmt.Errorf("invalid stream %s: %w", name, err)
+		}
+	}
+
 	// 验证日志级别
 	if len(c.Level) > 0 {
 		if err := CheckLogLevelStr(c.Level); err != nil {
@@ -89,3 +311,66 @@ func (c *Config) ValidateConfig() error {
 
 	return nil
 }
+
+// validate 验证单个日志流的轮转和保留配置
+func (s StreamConfig) validate() error {
+	if s.MaxSize > 0 && (s.MaxSize < minLogSize || s.MaxSize > maxLogSize) {
+		return fmt.Errorf("invalid max size: %d, should be between %d and %d MB",
+			s.MaxSize, minLogSize, maxLogSize)
+	}
+	if s.MaxBackups > 0 && (s.MaxBackups < minBackups || s.MaxBackups > maxBackups) {
+		return fmt.Errorf("invalid max backups: %d, should be between %d and %d",
+			s.MaxBackups, minBackups, maxBackups)
+	}
+	if s.KeepDays < 0 || s.MaxAge < 0 || s.MaxTotalSize < 0 || s.MaxSize < 0 || s.MaxBackups < 0 {
+		return fmt.Errorf("invalid stream config: %+v, should not be negative", s)
+	}
+	if len(s.Rotation) > 0 && s.Rotation != rotationSize && s.Rotation != rotationTime {
+		return fmt.Errorf("invalid rotation: %s, should be either '%s' or '%s'",
+			s.Rotation, rotationSize, rotationTime)
+	}
+
+	return nil
+}
+
+// validate 验证分流规则
+func (r RouteRule) validate() error {
+	if err := validateStreamName(r.Name); err != nil {
+		return err
+	}
+	for _, level := range r.Levels {
+		if err := CheckLogLevelStr(level); err != nil {
+			return fmt.Errorf("invalid level in route %s: %s", r.Name, level)
+		}
+	}
+	if err := r.StreamConfig.validate(); err != nil {
+		return fmt.Errorf("invalid route %s: %w", r.Name, err)
+	}
+
+	return nil
+}
+
+// validateStreamName 验证分流规则和命名日志流的名称
+func validateStreamName(name string) error {
+	if len(name) == 0 || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid stream name: %q, should be non-empty without path separators", name)
+	}
+	if _, ok := reservedStreamNames[name]; ok {
+		return fmt.Errorf("stream name is reserved: %s", name)
+	}
+
+	return nil
+}
+
+// toInternal 转换为内部的日志流配置
+func (s StreamConfig) toInternal() internal.StreamConf {
+	return internal.StreamConf{
+		Rotation:     s.Rotation,
+		MaxSize:      s.MaxSize,
+		MaxBackups:   s.MaxBackups,
+		KeepDays:     s.KeepDays,
+		MaxAge:       s.MaxAge,
+		MaxTotalSize: s.MaxTotalSize,
+		Compress:     s.Compress,
+	}
+}
@@ -0,0 +1,61 @@
+package qlog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/FortuneW/qlog/internal"
+)
+
+// crashFlushTimeout 崩溃时同步刷新日志文件的最长等待时间
+const crashFlushTimeout = 3 * time.Second
+
+// RecoverAndLog 恢复当前协程的 panic，将 panic 值、所有协程的调用栈和内存中的最近日志写入错误日志，
+// 并同步刷新所有日志文件，logger 为 nil 时使用 qlog 模块日志
+// 必须直接 defer 调用：defer qlog.RecoverAndLog(logger)
+func RecoverAndLog(logger RLogger) {
+	if r := recover(); r != nil {
+		logPanic(logger, r)
+	}
+}
+
+// CrashHook 与 RecoverAndLog 相同，但在记录并刷新日志后重新抛出 panic，进程按原有方式退出
+// 用于 main 函数：defer qlog.CrashHook(logger)
+// 重新抛出后运行时输出的 panic 信息带有 [recovered] 标记，调用栈最上面是 CrashHook 本身，
+// 原始 panic 的位置在其下方的 panic(...) 之后，写入错误日志的调用栈同样如此
+// 只能处理 main 协程中的 panic，其他协程需要使用 Go 启动或者自行 defer RecoverAndLog，
+// 否则只能通过 CrashLog 由运行时写入崩溃日志，此时不会写入最近日志，写入通道中的日志也不会刷新到文件
+func CrashHook(logger RLogger) {
+	if r := recover(); r != nil {
+		logPanic(logger, r)
+		panic(r)
+	}
+}
+
+// Go 启动一个协程执行 fn，fn 中的 panic 由 RecoverAndLog 处理：
+// 写入 panic 值、所有协程的调用栈和最近日志并刷新日志文件，进程继续运行
+func Go(logger RLogger, fn func()) {
+	go func() {
+		defer RecoverAndLog(logger)
+		fn()
+	}()
+}
+
+func logPanic(logger RLogger, r any) {
+	if logger == nil {
+		logger = mlog
+	}
+
+	// 调用栈和最近日志本身是多行的，作为详情追加，不会被转义成一行
+	logger.Errorf("panic: %v", r, internal.WithDetail(string(internal.AllStacks())))
+
+	if ring := internal.RecentRing(); ring != nil {
+		var recent strings.Builder
+		_, _ = ring.WriteTo(&recent)
+		if recent.Len() > 0 {
+			logger.Errorf("recent records before panic:", internal.WithDetail(recent.String()))
+		}
+	}
+
+	_ = internal.FlushAll(crashFlushTimeout)
+}
@@ -0,0 +1,130 @@
+package qlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FortuneW/qlog/internal"
+)
+
+type panicRecorder struct {
+	RLogger
+	lock   sync.Mutex
+	errors []string
+}
+
+func (p *panicRecorder) Errorf(format string, args ...interface{}) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	args, detail := internal.SplitDetail(args)
+	p.errors = append(p.errors, fmt.Sprintf(format, args...)+"\n"+detail)
+}
+
+func (p *panicRecorder) count() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.errors)
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	recorder := &panicRecorder{}
+
+	func() {
+		defer RecoverAndLog(recorder)
+		panic("something bad")
+	}()
+
+	if len(recorder.errors) == 0 {
+		t.Fatal("panic应该被记录")
+	}
+	if !strings.HasPrefix(recorder.errors[0], "panic: something bad\ngoroutine ") {
+		t.Errorf("期望记录panic值和协程调用栈, 得到 %q", recorder.errors[0])
+	}
+}
+
+func TestCrashHook(t *testing.T) {
+	recorder := &panicRecorder{}
+
+	defer func() {
+		if r := recover(); r != "fatal" {
+			t.Errorf("CrashHook应该重新抛出panic, 得到 %v", r)
+		}
+		if len(recorder.errors) == 0 {
+			t.Error("panic应该被记录")
+		}
+	}()
+
+	func() {
+		defer CrashHook(recorder)
+		panic("fatal")
+	}()
+}
+
+func TestRecoverAndLog_Flush(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "flush.log")
+	logger, err := internal.NewLogger(file, internal.DefaultRotateRule(file, "-", 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 100; i++ {
+		logger.Write([]byte("before panic\n"))
+	}
+
+	func() {
+		defer RecoverAndLog(&panicRecorder{})
+		panic("flush")
+	}()
+
+	// RecoverAndLog 返回时写入通道中的日志已经落盘
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if count := strings.Count(string(content), "before panic\n"); count != 100 {
+		t.Errorf("panic 前写入的日志应该全部落盘, 得到 %d 行", count)
+	}
+}
+
+func TestGo(t *testing.T) {
+	recorder := &panicRecorder{}
+	Go(recorder, func() {
+		panic("in goroutine")
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for recorder.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if recorder.count() == 0 || !strings.Contains(recorder.errors[0], "panic: in goroutine") {
+		t.Errorf("协程中的panic应该被记录, 得到 %q", recorder.errors)
+	}
+}
+
+func TestRecoverAndLog_ELog(t *testing.T) {
+	eLog := GetELog("crash")
+	func() {
+		defer RecoverAndLog(eLog)
+		panic("forged\n[ERR] line")
+	}()
+
+	// 子进程中 panic 值被转义，调用栈保留换行
+	select {
+	case item := <-eLog.GetPopELogItemChannel():
+		if !strings.Contains(item.Content, `panic: forged\n[ERR] line`+"\ngoroutine ") {
+			t.Errorf("期望转义 panic 值并保留调用栈的换行, 得到 %q", item.Content)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic 应该写入子进程日志通道")
+	}
+	for len(eLog.GetPopELogItemChannel()) > 0 {
+		<-eLog.GetPopELogItemChannel()
+	}
+}
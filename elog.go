@@ -2,6 +2,8 @@ package qlog
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/FortuneW/qlog/internal"
 )
@@ -17,7 +19,14 @@ type ELogItem struct {
 	Content string
 }
 
-var eLogItems = make(chan *ELogItem, 1024)
+var (
+	eLogItems = make(chan *ELogItem, 1024)
+	// exitFunc 用于 Fatal 退出进程，测试时可以替换
+	exitFunc = os.Exit
+)
+
+// eLogDrainTimeout Panic/Fatal 时等待主进程取走通道中日志的最长时间
+const eLogDrainTimeout = 3 * time.Second
 
 func sendToELogItems(item *ELogItem) {
 	select {
@@ -71,13 +80,14 @@ func (e eLogger) Warn(args ...interface{}) {
 }
 
 func (e eLogger) Error(args ...interface{}) {
+	args, detail := internal.SplitDetail(args)
 	val := fmt.Sprint(args...)
 	if len(val) == 0 {
 		return
 	}
 	sendToELogItems(&ELogItem{
 		Level:   internal.ErrorLevel,
-		Content: internal.GetOutputStringFormatted(internal.LevelError, e.formatMessage(val)),
+		Content: internal.GetOutputStringWithDetail(internal.LevelError, e.formatMessage(val), detail),
 	})
 }
 
@@ -126,16 +136,81 @@ func (e eLogger) Warnf(format string, args ...interface{}) {
 }
 
 func (e eLogger) Errorf(format string, args ...interface{}) {
+	args, detail := internal.SplitDetail(args)
 	val := fmt.Sprintf(format, args...)
 	if len(val) == 0 {
 		return
 	}
 	sendToELogItems(&ELogItem{
 		Level:   internal.ErrorLevel,
-		Content: internal.GetOutputStringFormatted(internal.LevelError, e.formatMessage(val)),
+		Content: internal.GetOutputStringWithDetail(internal.LevelError, e.formatMessage(val), detail),
 	})
 }
 
+func (e eLogger) Panic(args ...interface{}) {
+	e.panic(fmt.Sprint(args...))
+}
+
+func (e eLogger) Panicf(format string, args ...interface{}) {
+	e.panic(fmt.Sprintf(format, args...))
+}
+
+func (e eLogger) Fatal(args ...interface{}) {
+	e.fatal(fmt.Sprint(args...))
+}
+
+func (e eLogger) Fatalf(format string, args ...interface{}) {
+	e.fatal(fmt.Sprintf(format, args...))
+}
+
+func (e eLogger) Log(level uint32, args ...interface{}) {
+	val := fmt.Sprint(args...)
+	if len(val) == 0 {
+		return
+	}
+	sendToELogItems(&ELogItem{
+		Level:   level,
+		Content: internal.GetOutputStringFormatted(internal.LevelTag(level), e.formatMessage(val)),
+	})
+}
+
+func (e eLogger) Logf(level uint32, format string, args ...interface{}) {
+	val := fmt.Sprintf(format, args...)
+	if len(val) == 0 {
+		return
+	}
+	sendToELogItems(&ELogItem{
+		Level:   level,
+		Content: internal.GetOutputStringFormatted(internal.LevelTag(level), e.formatMessage(val)),
+	})
+}
+
+func (e eLogger) panic(val string) {
+	sendToELogItems(&ELogItem{
+		Level:   internal.PanicLevel,
+		Content: internal.GetOutputStringFormatted(internal.LevelPanic, e.formatMessage(val)),
+	})
+	waitELogItemsDrained(eLogDrainTimeout)
+	panic(val)
+}
+
+func (e eLogger) fatal(val string) {
+	sendToELogItems(&ELogItem{
+		Level:   internal.FatalLevel,
+		Content: internal.GetOutputStringFormatted(internal.LevelFatal, e.formatMessage(val)),
+	})
+	waitELogItemsDrained(eLogDrainTimeout)
+	exitFunc(1)
+}
+
+// waitELogItemsDrained 等待主进程取走通道中的日志，超时后直接返回
+func waitELogItemsDrained(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for len(eLogItems) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // WriteELogItem elog 本身不用实现写入函数,统一由主进程的ALog负责写入
 func (e eLogger) WriteELogItem(item *ELogItem) {
 	return
@@ -244,3 +244,67 @@ func TestELogger_ChannelBuffer(t *testing.T) {
 		}
 	}
 }
+
+func TestELogger_PanicAndFatal(t *testing.T) {
+	eLog := &eLogger{moduleName: "elog_exit"}
+
+	var exitCode int
+	var queued int
+	oldExit := exitFunc
+	exitFunc = func(code int) {
+		exitCode = code
+		queued = len(eLogItems)
+	}
+	defer func() { exitFunc = oldExit }()
+
+	// 模拟主进程取走日志
+	received := make(chan *ELogItem, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			received <- <-eLog.GetPopELogItemChannel()
+		}
+	}()
+
+	eLog.Fatalf("fatal %s", "message")
+	if exitCode != 1 {
+		t.Errorf("Fatal 应该以退出码1退出, 得到 %d", exitCode)
+	}
+	if queued != 0 {
+		t.Errorf("退出前应该等待主进程取走日志, 剩余 %d 条", queued)
+	}
+	select {
+	case item := <-received:
+		assertLogItem(t, item, internal.FatalLevel, "elog_exit", "fatal message", "")
+	case <-time.After(time.Second):
+		t.Fatal("Fatal 日志没有写入通道")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "panic message" {
+				t.Errorf("Panic 应该在写入日志后 panic, 得到 %v", r)
+			}
+		}()
+		eLog.Panic("panic message")
+	}()
+	select {
+	case item := <-received:
+		assertLogItem(t, item, internal.PanicLevel, "elog_exit", "panic message", "")
+	case <-time.After(time.Second):
+		t.Fatal("Panic 日志没有写入通道")
+	}
+}
+
+func TestWaitELogItemsDrained_Timeout(t *testing.T) {
+	sendToELogItems(&ELogItem{Level: internal.ErrorLevel, Content: "pending"})
+	defer func() { <-eLogItems }()
+
+	start := time.Now()
+	waitELogItemsDrained(50 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("没有主进程取走日志时应该等待到超时, 用时 %v", elapsed)
+	}
+	if len(eLogItems) != 1 {
+		t.Errorf("超时后日志应该仍在通道中, 得到 %d 条", len(eLogItems))
+	}
+}
@@ -0,0 +1,31 @@
+package qlog
+
+import "github.com/FortuneW/qlog/internal"
+
+type (
+	// HealthState 表示单个日志输出的健康状态
+	HealthState = internal.HealthState
+	// HealthStatus 描述单个日志输出的健康状况
+	HealthStatus = internal.HealthStatus
+	// HealthEvent 描述日志输出健康状态的一次变化
+	HealthEvent = internal.HealthEvent
+)
+
+// 日志输出的健康状态
+const (
+	HealthStateHealthy    = internal.HealthStateHealthy    // 正常
+	HealthStateDegraded   = internal.HealthStateDegraded   // 可以写入但处于受限状态
+	HealthStateRecovering = internal.HealthStateRecovering // 写入失败,正在恢复
+)
+
+// Health 返回所有文件输出的健康状况，包含最后一次错误、故障开始时间和累计故障时长
+// console 模式下没有文件输出，返回空
+func Health() []HealthStatus {
+	return internal.Health()
+}
+
+// OnHealthChange 订阅日志输出健康状态变化，可用于切换服务就绪探针或告警，返回取消订阅的函数
+// 回调在健康检查协程中同步执行，不要在回调中阻塞
+func OnHealthChange(fn func(event HealthEvent)) (cancel func()) {
+	return internal.SubscribeHealth(fn)
+}
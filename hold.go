@@ -0,0 +1,28 @@
+package qlog
+
+import "github.com/FortuneW/qlog/internal"
+
+// DiskPressure 描述保全期间被阻止删除的归档文件
+type DiskPressure = internal.DiskPressure
+
+// Hold 冻结所有日志文件的保留策略（事件调查等场景），期间不会删除任何归档文件
+// 同时在日志目录下写入 .qlog-hold 标记文件，运维人员也可以手工创建该文件冻结单个目录
+func Hold(reason string) error {
+	return internal.Hold(reason)
+}
+
+// Release 解除保全，删除日志目录下的标记文件，保留策略在下一次检查时恢复
+func Release() error {
+	return internal.Release()
+}
+
+// IsHeld 返回是否通过 Hold 冻结了保留策略
+func IsHeld() bool {
+	held, _, _ := internal.HoldStatus()
+	return held
+}
+
+// OnDiskPressure 设置保全期间保留策略被阻止时的回调，用于告警而不是静默删除文件
+func OnDiskPressure(fn func(pressure DiskPressure)) {
+	internal.SetDiskPressureHandler(fn)
+}
@@ -0,0 +1,26 @@
+package qlog
+
+import "github.com/FortuneW/qlog/internal"
+
+type (
+	// Record 是编码前的一条日志，钩子可以修改 Message 和 Fields
+	Record = internal.Record
+	// Hook 在日志编码前调用，返回 false 时丢弃这条日志
+	Hook = internal.Hook
+)
+
+// AccessLevel 是访问日志和命名日志流传给钩子的 Record.Level
+const AccessLevel = internal.AccessLevel
+
+// AddHook 添加一个日志钩子，按添加顺序在每条日志编码前调用，包括 Print/Printf 写入的访问日志和 GetStream 写入的命名日志流
+// 子进程通过 ELogItem 转发的已格式化日志不经过钩子
+// 可以用于补充字段、改写消息、丢弃日志或者同时发送到其他通道
+// 钩子在写日志的协程中同步执行，需要支持并发调用，不要在钩子中阻塞或者写日志
+func AddHook(hook func(record *Record) (keep bool)) {
+	internal.AddHook(hook)
+}
+
+// ResetHooks 移除所有日志钩子
+func ResetHooks() {
+	internal.ResetHooks()
+}
@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+)
+
+// captureOutput 设置日志级别并将写入器替换为内存缓冲区，测试结束后恢复原来的写入器和日志级别
+func captureOutput(t *testing.T, level uint32) *bytes.Buffer {
+	t.Helper()
+
+	oldLevel := GetLevel()
+	old := Reset()
+	SetLevel(level)
+
+	var buf bytes.Buffer
+	SetWriter(NewWriter(&buf))
+	t.Cleanup(func() {
+		Reset()
+		if old != nil {
+			SetWriter(old)
+		}
+		SetLevel(oldLevel)
+	})
+
+	return &buf
+}
@@ -68,6 +68,30 @@ func (w *colorConsoleWriter) Info(v any) {
 	}
 }
 
+func (w *colorConsoleWriter) Panic(v any) {
+	if isColorSupported() {
+		output(w.outLog, color.MagentaString(LevelPanic), v)
+	} else {
+		output(w.outLog, LevelPanic, v)
+	}
+}
+
+func (w *colorConsoleWriter) Fatal(v any) {
+	if isColorSupported() {
+		output(w.outLog, color.HiRedString(LevelFatal), v)
+	} else {
+		output(w.outLog, LevelFatal, v)
+	}
+}
+
+func (w *colorConsoleWriter) Log(level uint32, v any) {
+	if isColorSupported() {
+		output(w.outLog, coloredLevelTag(level), v)
+	} else {
+		output(w.outLog, levelTag(level), v)
+	}
+}
+
 func (w *colorConsoleWriter) AccessRecord(v any) {
 	if isColorSupported() {
 		output(w.outLog, levelAccessRecord, v)
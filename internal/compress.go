@@ -42,13 +42,23 @@ func gzipFile(file string, fsys fileSystem) (err error) {
 	}()
 
 	w := gzip.NewWriter(out)
-	if _, err = fsys.Copy(w, in); err != nil {
+	if _, err = fsys.Copy(w, compressor.throttle(in)); err != nil {
 		// failed to copy, no need to close w
 		return err
 	}
 
 	_ = out.Chmod(gzipFileMode)
-	return fsys.Close(w)
+	if err = fsys.Close(w); err != nil {
+		return err
+	}
+
+	// 压缩文件沿用原文件的修改时间，按 MaxAge 清理的备份不会因为压缩重新计时
+	if info, e := in.Stat(); e == nil {
+		if e = os.Chtimes(gzipFile, info.ModTime(), info.ModTime()); e != nil {
+			Errorf("failed to keep modification time: %s, error: %v", gzipFile, e)
+		}
+	}
+	return nil
 }
 
 // 确保有足够的磁盘空间
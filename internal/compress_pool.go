@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultCompressConcurrency = 1
+	compressReadChunkSize      = 32 * 1024
+)
+
+// compressor 是所有 RotateLogger 共享的压缩工作池
+var compressor = newCompressPool()
+
+type (
+	// compressTask 表示一个待压缩的归档文件
+	compressTask struct {
+		logger  *RotateLogger
+		file    string
+		retried bool
+	}
+
+	// compressPool 是有界的压缩工作池，工作协程数不超过 concurrency，空闲时自动退出
+	compressPool struct {
+		lock        sync.Mutex
+		queue       []compressTask
+		queued      map[string]PlaceholderType
+		workers     int
+		concurrency int
+		paused      bool
+		limiter     rateLimiter
+	}
+
+	// rateLimiter 用于限制压缩读取的字节速率，所有工作协程共享同一个配额
+	rateLimiter struct {
+		bytesPerSecond int64
+		lock           sync.Mutex
+		next           time.Time
+	}
+
+	throttledReader struct {
+		reader  io.Reader
+		limiter *rateLimiter
+	}
+)
+
+func newCompressPool() *compressPool {
+	return &compressPool{
+		queued:      make(map[string]PlaceholderType),
+		concurrency: defaultCompressConcurrency,
+	}
+}
+
+// setConcurrency 设置最大压缩并发数，小于等于0时使用默认值
+func (p *compressPool) setConcurrency(n int) {
+	if n <= 0 {
+		n = defaultCompressConcurrency
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.concurrency = n
+	p.spawnLocked()
+}
+
+// setRateLimit 设置压缩读取速率（字节/秒），小于等于0表示不限速
+func (p *compressPool) setRateLimit(bytesPerSecond int64) {
+	atomic.StoreInt64(&p.limiter.bytesPerSecond, bytesPerSecond)
+}
+
+// setPaused 暂停或恢复压缩，暂停期间任务保留在队列中，正在压缩的文件不受影响
+func (p *compressPool) setPaused(paused bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.paused = paused
+	p.spawnLocked()
+}
+
+// submit 提交一个压缩任务，同一个文件在队列中只会存在一份
+func (p *compressPool) submit(task compressTask) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.queued[task.file]; ok {
+		return
+	}
+
+	p.queued[task.file] = Placeholder
+	p.queue = append(p.queue, task)
+	p.spawnLocked()
+}
+
+// pending 返回排队中（尚未开始）的任务数量
+func (p *compressPool) pending() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.queue)
+}
+
+func (p *compressPool) spawnLocked() {
+	if p.paused {
+		return
+	}
+
+	for p.workers < p.concurrency && p.workers < len(p.queue) {
+		p.workers++
+		go p.work()
+	}
+}
+
+func (p *compressPool) take() (compressTask, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// 并发数被调小或者暂停时多余的协程直接退出
+	if len(p.queue) == 0 || p.workers > p.concurrency || p.paused {
+		p.workers--
+		return compressTask{}, false
+	}
+
+	task := p.queue[0]
+	p.queue = p.queue[1:]
+	delete(p.queued, task.file)
+	return task, true
+}
+
+func (p *compressPool) work() {
+	for {
+		task, ok := p.take()
+		if !ok {
+			return
+		}
+
+		p.run(task)
+	}
+}
+
+func (p *compressPool) run(task compressTask) {
+	l := task.logger
+
+	// 日志器已关闭的任务直接丢弃，遗留文件在下次启动时由 compressUncompressedFiles 处理
+	select {
+	case <-l.done:
+		return
+	default:
+	}
+
+	if retry := l.maybeCompressFile(task.file); retry {
+		if task.retried {
+			return
+		}
+
+		Warnf("clean some old files and retry compress file: %s", task.file)
+		l.maybeDeleteOutdatedFiles()
+		task.retried = true
+		p.submit(task)
+		return
+	}
+
+	l.maybeDeleteOutdatedFiles()
+}
+
+// throttle 返回按照当前速率限制读取的 reader
+func (p *compressPool) throttle(r io.Reader) io.Reader {
+	if atomic.LoadInt64(&p.limiter.bytesPerSecond) <= 0 {
+		return r
+	}
+
+	return &throttledReader{
+		reader:  r,
+		limiter: &p.limiter,
+	}
+}
+
+// wait 为读取 n 个字节预留配额，并等待到配额可用
+func (r *rateLimiter) wait(n int) {
+	rate := atomic.LoadInt64(&r.bytesPerSecond)
+	if rate <= 0 || n <= 0 {
+		return
+	}
+
+	cost := time.Duration(float64(n) / float64(rate) * float64(time.Second))
+
+	r.lock.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	r.next = r.next.Add(cost)
+	delay := r.next.Sub(now)
+	r.lock.Unlock()
+
+	time.Sleep(delay)
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > compressReadChunkSize {
+		p = p[:compressReadChunkSize]
+	}
+
+	n, err := t.reader.Read(p)
+	t.limiter.wait(n)
+	return n, err
+}
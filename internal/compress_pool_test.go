@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressPool_CompressFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "pool.log")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, true), true)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	pool := newCompressPool()
+	pool.setConcurrency(2)
+
+	var files []string
+	for i := 0; i < 5; i++ {
+		file := filepath.Join(tmpDir, fmt.Sprintf("archive-%d.log", i))
+		if err := os.WriteFile(file, []byte(strings.Repeat("x", 1024)), defaultFileMode); err != nil {
+			t.Fatalf("创建备份文件失败: %v", err)
+		}
+		files = append(files, file)
+		pool.submit(compressTask{logger: logger, file: file})
+		// 重复提交的文件只压缩一次
+		pool.submit(compressTask{logger: logger, file: file})
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, file := range files {
+		for {
+			if _, err := os.Stat(file + gzipExt); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("文件未被压缩: %s", file)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if n := pool.pending(); n != 0 {
+		t.Errorf("期望队列为空, 得到 %d", n)
+	}
+}
+
+func TestCompressPool_Throttle(t *testing.T) {
+	pool := newCompressPool()
+
+	data := bytes.Repeat([]byte("a"), 64*1024)
+	if r := pool.throttle(bytes.NewReader(data)); r == nil {
+		t.Fatal("throttle 返回了 nil")
+	} else if _, ok := r.(*throttledReader); ok {
+		t.Error("未设置限速时不应该包装 reader")
+	}
+
+	pool.setRateLimit(256 * 1024)
+	start := time.Now()
+	n, err := io.Copy(io.Discard, pool.throttle(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("期望读取 %d 字节, 得到 %d", len(data), n)
+	}
+
+	// 64KB / 256KB/s = 250ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("限速未生效, 耗时 %v", elapsed)
+	}
+}
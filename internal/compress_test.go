@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGzipFile_KeepModTime(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backlog.log")
+	if err := os.WriteFile(file, []byte("old records\n"), defaultFileMode); err != nil {
+		t.Fatalf("创建备份文件失败: %v", err)
+	}
+	modTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("修改文件时间失败: %v", err)
+	}
+
+	if err := gzipFile(file, fileSys); err != nil {
+		t.Fatalf("压缩失败: %v", err)
+	}
+
+	info, err := os.Stat(file + gzipExt)
+	if err != nil {
+		t.Fatalf("压缩文件不存在: %v", err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("压缩文件应沿用原文件的修改时间 %v, 得到 %v", modTime, info.ModTime())
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("压缩成功后应删除原文件, err: %v", err)
+	}
+}
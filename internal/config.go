@@ -1,5 +1,7 @@
 package internal
 
+import "time"
+
 // LogConf 是日志配置结构体
 type LogConf struct {
 	// ServiceName 表示服务名称
@@ -14,13 +16,20 @@ type LogConf struct {
 	ManagerLogDir string `json:",default=logs"`
 	// Level 表示日志级别，默认为 `ERR`
 	Level string `json:",default=ERR,options=[DEB,INF,WAR,ERR]"`
-	// MaxContentLength 表示最大内容字节数，默认无限制
+	// MaxContentLength 表示单条日志内容的最大字节数，超过时在字符边界截断并标记截断的字节数，默认无限制
+	// 对调用方的消息、JSON 编码的值和钩子添加的字段值都有效，追加的错误原因链和调用栈不截断
 	MaxContentLength uint32 `json:",optional"`
 	// Compress 表示是否压缩日志文件，默认为 `false`
 	Compress bool `json:",optional"`
+	// CompressConcurrency 表示压缩工作池的最大并发数，所有日志文件共享，默认为1
+	CompressConcurrency int `json:",optional"`
+	// CompressRateLimit 表示压缩时读取文件的速率上限，单位为字节/秒，默认不限速
+	CompressRateLimit int64 `json:",optional"`
 	// KeepDays 表示日志文件保留天数，默认保留所有文件
 	// 仅在 Mode 为 `file` 时生效，对 Rotation 为 `daily` 或 `size` 都有效
 	KeepDays int `json:",optional"`
+	// MaxAge 表示日志文件最长保留时长，按文件修改时间判断，设置后优先于 KeepDays
+	MaxAge time.Duration `json:",optional"`
 	// MaxBackups 表示要保留的备份日志文件数量，0表示永久保留所有文件
 	// 仅在 RotationRuleType 为 `size` 时生效
 	// 即使 MaxBackups 设置为0，如果达到 KeepDays 限制，日志文件仍会被删除
@@ -28,10 +37,81 @@ type LogConf struct {
 	// MaxSize 表示正在写入的日志文件可占用的最大空间，0表示无限制，单位为MB
 	// 仅在 RotationRuleType 为 `size` 时生效
 	MaxSize int `json:",default=0"`
+	// MaxTotalSize 表示单个日志流（备份文件与当前文件）可占用的最大空间，0表示无限制，单位为MB
+	// 服务日志和管理日志分别计算，对 Rotation 为 `daily` 或 `size` 都有效
+	MaxTotalSize int `json:",optional"`
+	// DiskThreshold 表示归档文件占用磁盘容量的百分比阈值，超过后删除最旧的备份，默认为80
+	DiskThreshold int `json:",optional"`
+	// DiskHighWatermark 表示磁盘使用率的高水位百分比，超过后进入磁盘压力保护模式，0表示不开启
+	// 保护模式每10秒检查一次，逐级提升日志级别、暂停压缩、删除最旧的备份
+	DiskHighWatermark int `json:",optional"`
+	// DiskLowWatermark 表示磁盘使用率的低水位百分比，低于后退出保护模式，默认与 DiskHighWatermark 相同
+	DiskLowWatermark int `json:",optional"`
+	// DiskPressureLevel 表示磁盘压力保护时提升到的日志级别，默认为 `INF`
+	DiskPressureLevel string `json:",optional"`
+	// RetentionDryRun 表示保留策略演练模式，开启后只记录删除决策而不真正删除文件
+	RetentionDryRun bool `json:",optional"`
 	// Rotation 表示日志轮转规则类型，默认为 `daily`
 	// daily: 按天轮转
 	// size: 按大小轮转
 	Rotation string `json:",default=daily,options=[daily,size]"`
+	// FallbackDir 表示主日志目录故障时的备用目录，默认不使用
+	FallbackDir string `json:",optional"`
+	// FallbackStderr 表示主日志目录故障时是否输出到标准错误，默认为 `false`
+	FallbackStderr bool `json:",optional"`
+	// FallbackBufferSize 表示主日志目录故障期间在内存中缓存的日志条数，恢复后按顺序回放到主日志文件
+	// 以上三项都未配置时，故障期间写入阻塞等待恢复，缓冲通道满后丢弃日志
+	FallbackBufferSize int `json:",optional"`
+	// HandleSignals 表示是否监听信号，SIGHUP 重新打开日志文件，SIGUSR1 轮转日志文件
+	// 仅在 Mode 为 `file` 时生效，用于配合外部 logrotate
+	HandleSignals bool `json:",optional"`
+	// RecentRecords 表示在内存中保留最近日志的条数，与 RecentBytes 都为0时不保留
+	RecentRecords int `json:",optional"`
+	// RecentBytes 表示在内存中保留最近日志的字节数
+	RecentBytes int `json:",optional"`
+	// RecentLevel 表示写入最近日志缓冲区的最低级别，不受 Level 限制，默认为 `TRA`
+	RecentLevel string `json:",optional"`
+	// TraceBufferRecords 表示每个链路在内存中缓存的低于 Level 的日志条数，0表示不缓存
+	// 使用 WithTraceId 的日志在链路正常结束(FinishTrace)时丢弃，链路中出现 ERR 时写入日志文件
+	TraceBufferRecords int `json:",optional"`
+	// TraceBufferTraces 表示同时缓存的链路数量上限，超过后丢弃最久未写入的链路，默认为1000
+	TraceBufferTraces int `json:",optional"`
+	// TraceBufferTimeout 表示链路未结束时的最长缓存时间，默认为1分钟
+	TraceBufferTimeout time.Duration `json:",optional"`
+	// StackLevel 表示附加调用栈和错误原因链的最低日志级别，例如 `ERR`，默认不附加
+	// 也可以在单次调用中传入 WithStack() 参数附加
+	StackLevel string `json:",optional"`
+	// CrashLog 表示是否将进程崩溃时运行时输出的错误和所有协程的调用栈写入服务日志目录下的 `<ServiceName>_crash.log`
+	// 由运行时直接写入，不包含最近日志，也不会刷新尚未落盘的日志
+	// 仅在 Mode 为 `file` 时生效
+	CrashLog bool `json:",optional"`
+	// Routes 表示按模块和级别分流的规则，匹配的日志写入服务日志目录下单独轮转的 `<ServiceName>_<Name>.log`
+	// 按顺序匹配第一条规则，仅在 Mode 为 `file` 时生效
+	Routes []RouteConf `json:",optional"`
+	// Server 表示服务日志单独的轮转和保留配置，未配置的项使用全局配置
+	Server StreamConf `json:",optional"`
+	// Manager 表示管理日志单独的轮转和保留配置，未配置的项使用全局配置
+	Manager StreamConf `json:",optional"`
+	// ErrorLog 表示是否将服务日志中 WAR 及以上的日志同时写入服务日志目录下的 `<ServiceName>_error.log`
+	// 仅在 Mode 为 `file` 时生效
+	ErrorLog bool `json:",optional"`
+	// ErrorStream 表示错误日志单独的轮转和保留配置，未配置的项使用全局配置
+	ErrorStream StreamConf `json:",optional"`
+	// Redact 表示是否开启内置的脱敏规则：银行卡号、邮箱、手机号、Bearer 令牌以及 password 等键值
+	Redact bool `json:",optional"`
+	// RedactPatterns 表示自定义的脱敏正则，匹配的内容替换为 `******`
+	RedactPatterns []string `json:",optional"`
+	// RedactFields 表示需要脱敏的字段名，钩子添加的字段以及消息中同名 key=value 的值被替换
+	RedactFields []string `json:",optional"`
+	// Sanitize 表示消息中换行和控制字符的处理方式，默认为 `escape`
+	// `escape` 转义为 `\n`、`\x1b` 等，`multiline` 保留换行并缩进后续行，`off` 原样写入
+	Sanitize string `json:",default=escape,options=[escape,multiline,off]"`
+	// StripAnsi 表示是否删除消息中的 ANSI 转义序列，默认转义
+	StripAnsi bool `json:",optional"`
+	// StreamDir 表示通过 GetStream 获取的命名日志流的目录，默认与 ServerLogDir 相同
+	StreamDir string `json:",optional"`
+	// Streams 表示命名日志流单独的轮转和保留配置，键为日志流名称
+	Streams map[string]StreamConf `json:",optional"`
 	// colorConsole 表示是否在控制台输出彩色日志，默认为 `false`
 	ColorConsole bool `json:",default=false"`
 }
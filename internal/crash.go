@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+)
+
+const initialStackSize = 64 * 1024
+
+// AllStacks 返回所有协程的调用栈
+func AllStacks() []byte {
+	buf := make([]byte, initialStackSize)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// SetCrashOutput 将进程崩溃(未恢复的 panic、fatal error)时运行时输出的错误和所有协程的调用栈追加写入 file
+// 用于记录任意协程中没有被 RecoverAndLog 处理的 panic
+// 崩溃时由运行时直接写入 file，不经过日志系统：不写入最近日志和错误日志，写入通道中尚未落盘的日志会丢失
+func SetCrashOutput(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), defaultDirMode); err != nil {
+		return err
+	}
+
+	fp, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, defaultFileMode)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	debug.SetTraceback("all")
+	return debug.SetCrashOutput(fp, debug.CrashOptions{})
+}
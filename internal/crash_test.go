@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetCrashOutput(t *testing.T) {
+	// 子进程中设置崩溃输出后在其他协程中 panic
+	if file := os.Getenv("QLOG_CRASH_FILE"); len(file) > 0 {
+		if err := SetCrashOutput(file); err != nil {
+			os.Exit(2)
+		}
+		go func() {
+			panic("crash in goroutine")
+		}()
+		select {}
+	}
+
+	file := filepath.Join(t.TempDir(), "logs", "svc_"+crashFilename)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSetCrashOutput$")
+	cmd.Env = append(os.Environ(), "QLOG_CRASH_FILE="+file)
+	if err := cmd.Run(); err == nil {
+		t.Fatal("子进程应该崩溃退出")
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("读取崩溃日志失败: %v", err)
+	}
+	if !strings.Contains(string(content), "panic: crash in goroutine") ||
+		!strings.Contains(string(content), "goroutine ") {
+		t.Errorf("崩溃日志应该包含 panic 和协程调用栈, 得到 %q", content)
+	}
+}
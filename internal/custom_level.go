@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// levelPriorityScale 内置级别的优先级为级别值乘以该系数，自定义级别可以插入到内置级别之间
+	levelPriorityScale = 100
+	// customLevelBase 是自定义级别值的起始值，避免与内置级别冲突
+	customLevelBase uint32 = 0x100
+	levelTagLength         = 3
+)
+
+var (
+	ErrInvalidLevelTag      = errors.New("level tag must be 3 upper case letters")
+	ErrLevelTagExists       = errors.New("level tag already exists")
+	ErrLevelPriorityInvalid = errors.New("level priority conflicts with an existing level")
+
+	customLevels    atomic.Value
+	customLevelLock sync.Mutex
+)
+
+type (
+	// customLevel 是通过 RegisterLevel 注册的日志级别
+	customLevel struct {
+		tag      string
+		priority uint32
+		color    *color.Color
+	}
+
+	customLevelSet struct {
+		byLevel map[uint32]customLevel
+		byTag   map[string]uint32
+	}
+)
+
+// RegisterLevel 注册一个自定义日志级别，返回级别值
+// priority 决定级别的高低，内置级别的优先级为 TRA=0、DEB=100、INF=200、WAR=300、ERR=400、PNC=500、FTL=600，
+// 例如 NOTICE 可以使用 250；tag 为三个大写字母，colors 为控制台输出的颜色
+func RegisterLevel(tag string, priority uint32, colors ...color.Attribute) (uint32, error) {
+	if !isValidLevelTag(tag) {
+		return 0, ErrInvalidLevelTag
+	}
+	if priority == math.MaxUint32 {
+		return 0, ErrLevelPriorityInvalid
+	}
+
+	customLevelLock.Lock()
+	defer customLevelLock.Unlock()
+
+	if _, ok := parseLevel(tag); ok {
+		return 0, ErrLevelTagExists
+	}
+	for level := TraceLevel; level <= FatalLevel; level++ {
+		if levelPriority(level) == priority {
+			return 0, fmt.Errorf("%w: %s", ErrLevelPriorityInvalid, levelTag(level))
+		}
+	}
+
+	set := loadCustomLevels()
+	for _, l := range set.byLevel {
+		if l.priority == priority {
+			return 0, fmt.Errorf("%w: %s", ErrLevelPriorityInvalid, l.tag)
+		}
+	}
+
+	next := customLevelSet{
+		byLevel: make(map[uint32]customLevel, len(set.byLevel)+1),
+		byTag:   make(map[string]uint32, len(set.byTag)+1),
+	}
+	for k, v := range set.byLevel {
+		next.byLevel[k] = v
+	}
+	for k, v := range set.byTag {
+		next.byTag[k] = v
+	}
+
+	level := customLevelBase + uint32(len(set.byLevel))
+	custom := customLevel{
+		tag:      tag,
+		priority: priority,
+	}
+	if len(colors) > 0 {
+		custom.color = color.New(colors...)
+	}
+	next.byLevel[level] = custom
+	next.byTag[tag] = level
+	customLevels.Store(next)
+
+	return level, nil
+}
+
+func isValidLevelTag(tag string) bool {
+	if len(tag) != levelTagLength {
+		return false
+	}
+	for _, c := range tag {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func loadCustomLevels() customLevelSet {
+	set, _ := customLevels.Load().(customLevelSet)
+	return set
+}
+
+// levelPriority 返回日志级别的优先级，用于比较级别高低
+func levelPriority(level uint32) uint32 {
+	if level <= FatalLevel {
+		return level * levelPriorityScale
+	}
+	if l, ok := loadCustomLevels().byLevel[level]; ok {
+		return l.priority
+	}
+
+	return math.MaxUint32
+}
+
+// levelAtLeast 返回 level 是否不低于 threshold，threshold 为 DisableLevel 时始终返回 false
+func levelAtLeast(level, threshold uint32) bool {
+	if threshold == DisableLevel {
+		return false
+	}
+
+	return levelPriority(threshold) <= levelPriority(level)
+}
+
+func customLevelTag(level uint32) (string, bool) {
+	l, ok := loadCustomLevels().byLevel[level]
+	return l.tag, ok
+}
+
+func customLevelByTag(tag string) (uint32, bool) {
+	level, ok := loadCustomLevels().byTag[tag]
+	return level, ok
+}
+
+// coloredLevelTag 返回控制台输出时带颜色的级别标签
+func coloredLevelTag(level uint32) string {
+	if l, ok := loadCustomLevels().byLevel[level]; ok && l.color != nil {
+		return l.color.Sprint(l.tag)
+	}
+
+	return levelTag(level)
+}
+
+// LevelTag 返回日志级别对应的标签，包括自定义级别，未知级别返回空
+func LevelTag(level uint32) string {
+	return levelTag(level)
+}
+
+// ParseLevel 将日志级别标签转换为级别值，包括自定义级别
+func ParseLevel(tag string) (uint32, bool) {
+	return parseLevel(tag)
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestRegisterLevel(t *testing.T) {
+	notice, ok := parseLevel("NTC")
+	if !ok {
+		var err error
+		if notice, err = RegisterLevel("NTC", 250, color.FgBlue); err != nil {
+			t.Fatalf("注册级别失败: %v", err)
+		}
+	}
+
+	if _, err := RegisterLevel("NTC", 260); !errors.Is(err, ErrLevelTagExists) {
+		t.Errorf("重复的标签应该注册失败, 得到 %v", err)
+	}
+	if _, err := RegisterLevel("ABC", 300); !errors.Is(err, ErrLevelPriorityInvalid) {
+		t.Errorf("与内置级别相同的优先级应该注册失败, 得到 %v", err)
+	}
+	if _, err := RegisterLevel("ab", 270); !errors.Is(err, ErrInvalidLevelTag) {
+		t.Errorf("非法的标签应该注册失败, 得到 %v", err)
+	}
+
+	if level, ok := parseLevel("ntc"); !ok || level != notice || levelTag(notice) != "NTC" {
+		t.Errorf("自定义级别应该可以通过标签解析, 得到 %d", level)
+	}
+
+	buf := captureOutput(t, InfoLevel)
+
+	logger := WithModuleName("level")
+	logger.Logf(notice, "user %s logged in", "alice")
+	if !strings.Contains(buf.String(), "[NTC] ") || !strings.Contains(buf.String(), "user alice logged in") {
+		t.Errorf("INF 级别下应该输出 NTC 日志, 得到 %q", buf.String())
+	}
+
+	buf.Reset()
+	SetLevel(notice)
+	logger.Info("info message")
+	logger.Log(notice, "notice message")
+	logger.Warn("warn message")
+	content := buf.String()
+	if strings.Contains(content, "info message") || !strings.Contains(content, "notice message") ||
+		!strings.Contains(content, "warn message") {
+		t.Errorf("NTC 级别应该位于 INF 和 WAR 之间, 得到 %q", content)
+	}
+
+	buf.Reset()
+	SetLevel(DisableLevel)
+	logger.Log(notice, "disabled")
+	logger.Log(0x1234, "unknown")
+	if buf.Len() != 0 {
+		t.Errorf("关闭日志后不应该输出, 得到 %q", buf.String())
+	}
+}
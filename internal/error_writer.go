@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"io"
+	"log"
+)
+
+// errorWriter 将服务日志中不低于 WAR 的日志同时写入单独的错误日志文件，管理日志不写入
+type errorWriter struct {
+	out   io.WriteCloser
+	level uint32
+}
+
+func newErrorWriter(out io.WriteCloser) Writer {
+	return &errorWriter{
+		out:   out,
+		level: WarnLevel,
+	}
+}
+
+func (w *errorWriter) write(level uint32, v any) {
+	if levelAtLeast(level, w.level) {
+		output(w.out, levelTag(level), v)
+	}
+}
+
+func (w *errorWriter) Close() error {
+	return w.out.Close()
+}
+
+func (w *errorWriter) Trace(v any) {
+	w.write(TraceLevel, v)
+}
+
+func (w *errorWriter) Debug(v any) {
+	w.write(DebugLevel, v)
+}
+
+func (w *errorWriter) Info(v any) {
+	w.write(InfoLevel, v)
+}
+
+func (w *errorWriter) Warn(v any) {
+	w.write(WarnLevel, v)
+}
+
+func (w *errorWriter) Error(v any) {
+	w.write(ErrorLevel, v)
+}
+
+func (w *errorWriter) Panic(v any) {
+	w.write(PanicLevel, v)
+}
+
+func (w *errorWriter) Fatal(v any) {
+	w.write(FatalLevel, v)
+}
+
+func (w *errorWriter) Log(level uint32, v any) {
+	w.write(level, v)
+}
+
+func (w *errorWriter) AccessRecord(any) {
+}
+
+// WriteRawString 写入子进程已格式化的日志，按行首的级别标签过滤
+func (w *errorWriter) WriteRawString(v string) {
+	level, ok := rawLevel(v)
+	if !ok || !levelAtLeast(level, w.level) {
+		return
+	}
+
+	if _, err := w.out.Write([]byte(v)); err != nil {
+		log.Println(err.Error())
+	}
+}
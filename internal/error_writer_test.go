@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error {
+	return nil
+}
+
+func TestErrorWriter(t *testing.T) {
+	server := captureOutput(t, InfoLevel)
+	var errLog bufferCloser
+	AddWriter(newErrorWriter(&errLog))
+
+	logger := WithModuleName("order")
+	logger.Info("order created")
+	logger.Warn("slow order")
+	logger.Error("order failed")
+	logger.Print("GET /orders")
+	logger.WriteRawString(GetOutputStringFormatted(LevelError, "child failed"))
+	logger.WriteRawString(GetOutputStringFormatted(LevelInfo, "child started"))
+
+	content := errLog.String()
+	for _, want := range []string{"slow order", "order failed", "child failed"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("错误日志缺少 %q: %q", want, content)
+		}
+	}
+	for _, unwanted := range []string{"order created", "GET /orders", "child started"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("错误日志不应包含 %q: %q", unwanted, content)
+		}
+	}
+	if !strings.Contains(server.String(), "order created") || !strings.Contains(server.String(), "slow order") {
+		t.Errorf("服务日志应包含所有日志: %q", server.String())
+	}
+}
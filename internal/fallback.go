@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// LoggerOption 定义了自定义 RotateLogger 的方法
+	LoggerOption func(logger *RotateLogger)
+
+	// fallbackOutput 在主日志目录故障期间接收日志，依次尝试备用目录和标准错误输出，
+	// 同时把日志保存在内存环形缓冲区中，恢复后按顺序回放到主日志文件
+	// 仅在写入协程中访问，无需加锁
+	fallbackOutput struct {
+		dir      string
+		stderr   bool
+		capacity int
+
+		fp       *os.File
+		records  [][]byte
+		start    int
+		count    int
+		dropped  int
+		since    time.Time
+		fileName string
+	}
+)
+
+// WithFallback 设置主日志目录故障时的备用输出
+// dir 为备用目录，stderr 表示是否输出到标准错误，bufferSize 为内存中保留用于回放的日志条数
+func WithFallback(dir string, stderr bool, bufferSize int) LoggerOption {
+	return func(logger *RotateLogger) {
+		if len(dir) == 0 && !stderr && bufferSize <= 0 {
+			return
+		}
+
+		logger.fallback = &fallbackOutput{
+			dir:      dir,
+			stderr:   stderr,
+			capacity: bufferSize,
+			fileName: filepath.Base(logger.filename),
+		}
+	}
+}
+
+// write 将日志写入备用输出并缓存用于回放
+func (f *fallbackOutput) write(v []byte) {
+	if f.since.IsZero() {
+		f.since = time.Now()
+	}
+
+	if !f.writeFile(v) && f.stderr {
+		_, _ = os.Stderr.Write(v)
+	}
+
+	f.push(v)
+}
+
+func (f *fallbackOutput) writeFile(v []byte) bool {
+	if len(f.dir) == 0 {
+		return false
+	}
+
+	if f.fp == nil {
+		if err := os.MkdirAll(f.dir, defaultDirMode); err != nil {
+			return false
+		}
+
+		fp, err := os.OpenFile(filepath.Join(f.dir, f.fileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY,
+			defaultFileMode)
+		if err != nil {
+			return false
+		}
+		CloseOnExec(fp)
+		f.fp = fp
+	}
+
+	if _, err := f.fp.Write(v); err != nil {
+		_ = f.fp.Close()
+		f.fp = nil
+		return false
+	}
+
+	return true
+}
+
+func (f *fallbackOutput) push(v []byte) {
+	if f.capacity <= 0 {
+		return
+	}
+
+	if f.count < f.capacity {
+		f.records = append(f.records, v)
+		f.count++
+		return
+	}
+
+	// 缓冲区已满，覆盖最旧的日志
+	f.records[f.start] = v
+	f.start = (f.start + 1) % f.capacity
+	f.dropped++
+}
+
+// take 按写入顺序取出缓存的日志并清空缓冲区
+func (f *fallbackOutput) take() (records [][]byte, dropped int, since time.Time) {
+	records = make([][]byte, 0, f.count)
+	for i := 0; i < f.count; i++ {
+		records = append(records, f.records[(f.start+i)%len(f.records)])
+	}
+	dropped, since = f.dropped, f.since
+
+	f.records = nil
+	f.start = 0
+	f.count = 0
+	f.dropped = 0
+	f.since = time.Time{}
+	return
+}
+
+func (f *fallbackOutput) close() {
+	if f.fp != nil {
+		_ = f.fp.Close()
+		f.fp = nil
+	}
+}
+
+// replayFallback 主日志恢复后，将故障期间缓存的日志按顺序写回主日志文件，并标记回放区间
+func (l *RotateLogger) replayFallback() {
+	l.degraded = false
+	defer l.health.ClearDegraded(degradedFallback)
+
+	records, dropped, since := l.fallback.take()
+	if len(records) == 0 && dropped == 0 {
+		return
+	}
+
+	begin := formatOutput(LevelWarn, fmt.Sprintf(
+		"[qlog] replay begin: %d records buffered during outage since %s, %d dropped",
+		len(records), since.UTC().Format(timeFormat), dropped))
+	l.write([]byte(begin))
+	for _, record := range records {
+		l.write(record)
+	}
+	l.write([]byte(formatOutput(LevelWarn, "[qlog] replay end")))
+}
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateLogger_FallbackReplay(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "primary", "fallback.log")
+	fallbackDir := filepath.Join(tmpDir, "fallback")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false), false,
+		WithFallback(fallbackDir, false, 10))
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before outage\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	// 模拟文件句柄损坏
+	logger.fp.Close()
+
+	logger.Write([]byte("during outage 1\n"))
+	logger.Write([]byte("during outage 2\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	content, err := os.ReadFile(filepath.Join(fallbackDir, "fallback.log"))
+	if err != nil {
+		t.Fatalf("读取备用日志文件失败: %v", err)
+	}
+	if string(content) != "during outage 1\nduring outage 2\n" {
+		t.Errorf("故障期间的日志应该写入备用目录, 得到 %q", content)
+	}
+
+	if state := logger.health.Status().State; state == HealthStateHealthy {
+		t.Errorf("写入备用输出期间不应该处于健康状态")
+	}
+
+	// 等待恢复并回放，回放后才解除受限状态
+	time.Sleep(time.Millisecond * 2500)
+	if state := logger.health.Status().State; state != HealthStateHealthy {
+		t.Errorf("回放后应该恢复健康, 得到 %s", state)
+	}
+
+	logger.Write([]byte("after recovery\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	content, err = os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+
+	text := string(content)
+	order := []string{"before outage", "replay begin: 2 records", "during outage 1", "during outage 2",
+		"replay end", "after recovery"}
+	last := -1
+	for _, s := range order {
+		idx := strings.Index(text, s)
+		if idx <= last {
+			t.Fatalf("回放顺序错误, 缺少或者乱序 %q\n%s", s, text)
+		}
+		last = idx
+	}
+}
+
+func TestFallbackOutput_RingBuffer(t *testing.T) {
+	f := &fallbackOutput{capacity: 2}
+	f.push([]byte("1"))
+	f.push([]byte("2"))
+	f.push([]byte("3"))
+
+	records, dropped, _ := f.take()
+	if len(records) != 2 || string(records[0]) != "2" || string(records[1]) != "3" {
+		t.Errorf("期望保留最新的两条日志, 得到 %q", records)
+	}
+	if dropped != 1 {
+		t.Errorf("期望丢弃1条日志, 得到 %d", dropped)
+	}
+
+	if records, _, _ := f.take(); len(records) != 0 {
+		t.Errorf("取出后缓冲区应该为空, 得到 %q", records)
+	}
+}
+
+func TestRotateLogger_ControlDuringOutage(t *testing.T) {
+	tmpDir := t.TempDir()
+	primaryDir := filepath.Join(tmpDir, "primary")
+	logFile := filepath.Join(primaryDir, "outage.log")
+	fallbackDir := filepath.Join(tmpDir, "fallback")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false), false,
+		WithFallback(fallbackDir, false, 10))
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before outage\n"))
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	// 日志目录被替换为普通文件，删除检测后恢复流程一直失败
+	if err := os.Rename(primaryDir, primaryDir+".old"); err != nil {
+		t.Fatalf("移动日志目录失败: %v", err)
+	}
+	writeTestFile(t, primaryDir, 0)
+
+	// 恢复流程和控制请求同时进行，文件句柄只在写入协程中修改，-race 下不应报告数据竞争
+	deadline := time.Now().Add(time.Millisecond * 2500)
+	for i := 0; time.Now().Before(deadline); i++ {
+		logger.Write([]byte(fmt.Sprintf("during outage %d\n", i)))
+		if err := logger.Flush(); err != nil {
+			t.Fatalf("故障期间 Flush 不应失败: %v", err)
+		}
+		_ = logger.Rotate()
+		time.Sleep(time.Millisecond * 50)
+	}
+	if state := logger.health.Status().State; state == HealthStateHealthy {
+		t.Fatalf("故障期间不应该处于健康状态")
+	}
+
+	if err := os.Remove(primaryDir); err != nil {
+		t.Fatalf("删除文件失败: %v", err)
+	}
+	time.Sleep(time.Millisecond * 1500)
+	logger.Write([]byte("after recovery\n"))
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if !strings.Contains(string(content), "replay begin") || !strings.Contains(string(content), "after recovery") {
+		t.Errorf("恢复后应该回放备用输出并继续写入, 得到 %q", content)
+	}
+}
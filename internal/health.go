@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// HealthStateHealthy 表示日志输出正常
+	HealthStateHealthy HealthState = iota
+	// HealthStateDegraded 表示日志可以写入但处于受限状态，例如保全期间的磁盘压力
+	HealthStateDegraded
+	// HealthStateRecovering 表示日志文件写入失败，正在尝试恢复
+	HealthStateRecovering
+)
+
+const (
+	// 受限状态的原因，解除时只解除对应的原因
+	degradedHeld       = "retention held, outdated files are kept"
+	degradedFallback   = "writing to fallback output"
+	degradedPressure   = "disk pressure protective mode"
+	degradedUnwritable = "log file is not writable, writing to the opened file"
+)
+
+var healthSubscribers struct {
+	lock   sync.RWMutex
+	nextId int
+	fns    map[int]func(event HealthEvent)
+}
+
+type (
+	// HealthState 表示单个日志输出的健康状态
+	HealthState int
+
+	// HealthStatus 描述单个日志输出的健康状况
+	HealthStatus struct {
+		File          string        // 日志文件路径
+		State         HealthState   // 当前状态
+		Reason        string        // 处于非健康状态的原因
+		LastError     error         // 最后一次错误
+		LastErrorTime time.Time     // 最后一次错误发生时间
+		OutageStart   time.Time     // 当前故障开始时间，健康时为零值
+		Downtime      time.Duration // 累计不可写入时长，包含当前故障
+	}
+
+	// HealthEvent 描述日志输出健康状态的一次变化
+	HealthEvent struct {
+		File   string      // 日志文件路径
+		From   HealthState // 变化前状态
+		To     HealthState // 变化后状态
+		Reason string      // 变化原因
+		Error  error       // 引起变化的错误，恢复时为 nil
+		Time   time.Time   // 变化时间
+	}
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthStateHealthy:
+		return "healthy"
+	case HealthStateDegraded:
+		return "degraded"
+	case HealthStateRecovering:
+		return "recovering"
+	default:
+		return "unknown"
+	}
+}
+
+// Health 返回所有文件输出的健康状况
+func Health() []HealthStatus {
+	var statuses []HealthStatus
+	for _, l := range rotateLoggers.snapshot() {
+		statuses = append(statuses, l.health.Status())
+	}
+
+	return statuses
+}
+
+// SubscribeHealth 订阅健康状态变化事件，返回取消订阅的函数
+// 回调在健康检查协程中同步执行，不要在回调中阻塞
+func SubscribeHealth(fn func(event HealthEvent)) (cancel func()) {
+	healthSubscribers.lock.Lock()
+	defer healthSubscribers.lock.Unlock()
+
+	if healthSubscribers.fns == nil {
+		healthSubscribers.fns = make(map[int]func(event HealthEvent))
+	}
+	id := healthSubscribers.nextId
+	healthSubscribers.nextId++
+	healthSubscribers.fns[id] = fn
+
+	return func() {
+		healthSubscribers.lock.Lock()
+		defer healthSubscribers.lock.Unlock()
+		delete(healthSubscribers.fns, id)
+	}
+}
+
+func publishHealth(event HealthEvent) {
+	healthSubscribers.lock.RLock()
+	fns := make([]func(event HealthEvent), 0, len(healthSubscribers.fns))
+	for _, fn := range healthSubscribers.fns {
+		fns = append(fns, fn)
+	}
+	healthSubscribers.lock.RUnlock()
+
+	for _, fn := range fns {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					writeError(r)
+				}
+			}()
+			fn(event)
+		}()
+	}
+}
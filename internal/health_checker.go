@@ -1,9 +1,12 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +18,15 @@ type HealthChecker struct {
 	errorTime   time.Time     // 错误发生时间
 	done        chan PlaceholderType
 	logger      *RotateLogger
+	deniedMode  os.FileMode // 已经请求过重新打开的不可写权限，重新打开失败时不再每秒重复请求
+	permDenied  bool        // deniedMode 是否有效
+
+	lock        sync.RWMutex  // 保护以下状态字段
+	state       HealthState   // 当前健康状态
+	reason      string        // 处于非健康状态的原因
+	degraded    []string      // 仍然有效的受限原因，按设置顺序
+	outageStart time.Time     // 当前故障开始时间
+	downtime    time.Duration // 已结束故障的累计时长
 }
 
 // NewHealthChecker 创建新的健康检查器
@@ -40,15 +52,16 @@ func (h *HealthChecker) Start() {
 		for {
 			select {
 			case err := <-h.healthChan:
+				h.lock.Lock()
 				h.lastError = err
 				h.errorTime = time.Now()
+				h.lock.Unlock()
 				log.Println("health check error:", err, h.errorTime)
+				h.transit(HealthStateRecovering, err.Error(), err)
 				h.tryRecover()
 			case <-ticker.C:
-				// 定期检查文件是否存在
-				if _, err := os.Stat(h.logger.filename); os.IsNotExist(err) {
-					h.ReportError(fmt.Errorf("log file does not exist: %v", err))
-				}
+				// 定期检查文件是否存在、是否被替换或截断
+				h.checkFile()
 			case <-h.done:
 				return
 			}
@@ -56,17 +69,71 @@ func (h *HealthChecker) Start() {
 	}()
 }
 
+// checkFile 检查日志路径是否仍然指向当前打开的文件
+// 文件被删除时进入恢复流程；被替换（设备号/inode 变化）、被截断或者变为不可写时重新打开
+// 变为不可写时已经打开的文件仍然可以写入，处于受限状态，重新打开失败时继续使用原来的文件
+func (h *HealthChecker) checkFile() {
+	info, err := os.Stat(h.logger.filename)
+	if os.IsNotExist(err) {
+		h.ReportError(fmt.Errorf("log file does not exist: %v", err))
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	opened := h.logger.openedInfo()
+	if opened == nil {
+		return
+	}
+
+	if isWritable(info.Mode()) && h.permDenied {
+		h.permDenied = false
+		h.ClearDegraded(degradedUnwritable)
+	}
+
+	switch {
+	case !os.SameFile(opened, info):
+		h.logger.requestReopen("log file replaced")
+	case info.Size() < atomic.LoadInt64(&h.logger.currentSize):
+		h.logger.requestReopen(fmt.Sprintf("log file truncated to %d bytes", info.Size()))
+	case isWritable(opened.Mode()) && !isWritable(info.Mode()):
+		// 同一不可写权限只请求一次，权限恢复或者再次变化后才重新请求
+		if h.permDenied && h.deniedMode == info.Mode() {
+			return
+		}
+		h.permDenied = true
+		h.deniedMode = info.Mode()
+		h.SetDegraded(degradedUnwritable)
+		h.logger.requestReopen(fmt.Sprintf("log file permission changed to %s", info.Mode().Perm()))
+	}
+}
+
+func isWritable(mode os.FileMode) bool {
+	return mode.Perm()&0o200 != 0
+}
+
 // tryRecover 尝试恢复日志系统
 func (h *HealthChecker) tryRecover() {
 	retryCount := 0
 
 	for {
-		recoverMsg := GetOutputStringFormatted(LevelError, fmt.Sprintf("[qlog] system recovered from(%s:%q), outage duration: %v",
+		recoverMsg := formatOutput(LevelError, fmt.Sprintf("[qlog] system recovered from(%s:%q), outage duration: %v",
 			h.errorTime.Format(timeFormat),
 			h.lastError,
 			time.Since(h.errorTime)),
 		)
-		if err := h.testWrite(recoverMsg); err == nil {
+		err := h.logger.recoverFile(recoverMsg)
+		if errors.Is(err, ErrLogFileClosed) {
+			return
+		}
+		if err == nil {
+			// 恢复写入后仍有受限原因时回到受限状态
+			if reason, ok := h.degradedReason(); ok {
+				h.transit(HealthStateDegraded, reason, nil)
+			} else {
+				h.transit(HealthStateHealthy, "recovered", nil)
+			}
 			// 恢复成功，发送恢复信号
 			select {
 			case h.recoverChan <- struct{}{}:
@@ -86,7 +153,7 @@ func (h *HealthChecker) tryRecover() {
 	}
 }
 
-// testWrite 测试写入功能
+// testWrite 重新打开日志文件并测试写入，只在写入协程中调用，避免与写入和轮转同时修改文件句柄
 func (h *HealthChecker) testWrite(msg string) error {
 	if h.logger.fp == nil {
 		if err := h.logger.initialize(); err != nil {
@@ -94,8 +161,9 @@ func (h *HealthChecker) testWrite(msg string) error {
 		}
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(h.logger.filename); os.IsNotExist(err) {
+	// 检查文件是否存在或者已经被替换
+	if info, err := os.Stat(h.logger.filename); os.IsNotExist(err) ||
+		(err == nil && !sameFile(h.logger.openedInfo(), info)) {
 		// 只在文件指针非空时才尝试关闭
 		if h.logger.fp != nil {
 			if err := h.logger.fp.Close(); err != nil {
@@ -108,8 +176,127 @@ func (h *HealthChecker) testWrite(msg string) error {
 		}
 	}
 
-	_, err := h.logger.fp.Write([]byte(msg))
-	return err
+	if _, err := h.logger.fp.Write([]byte(msg)); err != nil {
+		// 文件句柄已经损坏，关闭后在下一次重试时重新打开
+		_ = h.logger.fp.Close()
+		h.logger.fp = nil
+		return err
+	}
+
+	return nil
+}
+
+func sameFile(opened, current os.FileInfo) bool {
+	return opened == nil || os.SameFile(opened, current)
+}
+
+// Status 返回当前健康状况
+func (h *HealthChecker) Status() HealthStatus {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	status := HealthStatus{
+		File:          h.logger.filename,
+		State:         h.state,
+		Reason:        h.reason,
+		LastError:     h.lastError,
+		LastErrorTime: h.errorTime,
+		OutageStart:   h.outageStart,
+		Downtime:      h.downtime,
+	}
+	if !h.outageStart.IsZero() {
+		status.Downtime += time.Since(h.outageStart)
+	}
+
+	return status
+}
+
+// SetDegraded 记录一个受限原因，当前健康或者受限时切换到受限状态，恢复中时在恢复后生效
+func (h *HealthChecker) SetDegraded(reason string) {
+	h.lock.Lock()
+	exists := false
+	for _, r := range h.degraded {
+		if r == reason {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		h.degraded = append(h.degraded, reason)
+	}
+	state, old := h.state, h.reason
+	h.lock.Unlock()
+
+	if state == HealthStateHealthy || (state == HealthStateDegraded && old != reason && !exists) {
+		h.transit(HealthStateDegraded, reason, nil)
+	}
+}
+
+// ClearDegraded 解除 reason 对应的受限原因，没有其他受限原因时恢复健康
+func (h *HealthChecker) ClearDegraded(reason string) {
+	h.lock.Lock()
+	found := false
+	for i, r := range h.degraded {
+		if r == reason {
+			h.degraded = append(h.degraded[:i], h.degraded[i+1:]...)
+			found = true
+			break
+		}
+	}
+	state := h.state
+	h.lock.Unlock()
+
+	if !found || state != HealthStateDegraded {
+		return
+	}
+	if next, ok := h.degradedReason(); ok {
+		h.transit(HealthStateDegraded, next, nil)
+	} else {
+		h.transit(HealthStateHealthy, "", nil)
+	}
+}
+
+// degradedReason 返回最近设置且仍然有效的受限原因
+func (h *HealthChecker) degradedReason() (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.degraded) == 0 {
+		return "", false
+	}
+	return h.degraded[len(h.degraded)-1], true
+}
+
+// transit 切换健康状态并通知订阅者，不可写入期间计入累计故障时长
+func (h *HealthChecker) transit(to HealthState, reason string, err error) {
+	now := time.Now()
+
+	h.lock.Lock()
+	from := h.state
+	if from == to && h.reason == reason {
+		h.lock.Unlock()
+		return
+	}
+
+	h.state = to
+	h.reason = reason
+	switch {
+	case to == HealthStateRecovering && h.outageStart.IsZero():
+		h.outageStart = now
+	case to != HealthStateRecovering && !h.outageStart.IsZero():
+		h.downtime += now.Sub(h.outageStart)
+		h.outageStart = time.Time{}
+	}
+	h.lock.Unlock()
+
+	publishHealth(HealthEvent{
+		File:   h.logger.filename,
+		From:   from,
+		To:     to,
+		Reason: reason,
+		Error:  err,
+		Time:   now,
+	})
 }
 
 // ReportError 报告错误
@@ -119,13 +306,3 @@ func (h *HealthChecker) ReportError(err error) {
 	default:
 	}
 }
-
-// WaitRecover 等待恢复信号
-func (h *HealthChecker) WaitRecover() bool {
-	select {
-	case <-h.recoverChan:
-		return true
-	case <-h.done:
-		return false
-	}
-}
@@ -3,8 +3,12 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -83,3 +87,242 @@ func TestHealthChecker_MultipleErrors(t *testing.T) {
 		t.Errorf("期望错误 %v, 得到 %v", testErr, logger.health.lastError)
 	}
 }
+
+func TestHealthChecker_FileReplaced(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "replaced.log")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, ".", 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before move\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	// 模拟外部移走文件并创建同名新文件
+	if err := os.Rename(logFile, filepath.Join(tmpDir, "moved.log")); err != nil {
+		t.Fatalf("移动日志文件失败: %v", err)
+	}
+	if err := os.WriteFile(logFile, nil, defaultFileMode); err != nil {
+		t.Fatalf("创建日志文件失败: %v", err)
+	}
+
+	time.Sleep(time.Millisecond * 1500)
+	logger.Write([]byte("after replace\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if string(content) != "after replace\n" {
+		t.Errorf("替换后的日志应该写入新文件, 得到 %q", content)
+	}
+}
+
+func TestHealthChecker_FileTruncated(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "truncated.log")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, ".", 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before truncate\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	if err := os.Truncate(logFile, 0); err != nil {
+		t.Fatalf("截断日志文件失败: %v", err)
+	}
+
+	time.Sleep(time.Millisecond * 1500)
+	if size := atomic.LoadInt64(&logger.currentSize); size != 0 {
+		t.Errorf("截断后应该重新打开文件并重置大小, 得到 %d", size)
+	}
+
+	logger.Write([]byte("after truncate\n"))
+	time.Sleep(time.Millisecond * 100)
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if string(content) != "after truncate\n" {
+		t.Errorf("截断后的日志应该从文件开头写入, 得到 %q", content)
+	}
+}
+
+func TestHealthChecker_StatusAndEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "status.log")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, ".", 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	var lock sync.Mutex
+	var events []HealthEvent
+	cancel := SubscribeHealth(func(event HealthEvent) {
+		if event.File != logFile {
+			return
+		}
+		lock.Lock()
+		events = append(events, event)
+		lock.Unlock()
+	})
+	defer cancel()
+
+	testErr := errors.New("测试错误")
+	logger.health.ReportError(testErr)
+	time.Sleep(time.Millisecond * 100)
+
+	lock.Lock()
+	got := append([]HealthEvent(nil), events...)
+	lock.Unlock()
+	if len(got) != 2 || got[0].To != HealthStateRecovering || got[0].Error != testErr ||
+		got[1].To != HealthStateHealthy {
+		t.Fatalf("期望先进入恢复状态再恢复正常, 得到 %+v", got)
+	}
+
+	status := logger.health.Status()
+	if status.State != HealthStateHealthy || status.LastError != testErr || status.Downtime <= 0 {
+		t.Errorf("健康状况不符合预期: %+v", status)
+	}
+	if !status.OutageStart.IsZero() {
+		t.Errorf("恢复后故障开始时间应该为零值: %v", status.OutageStart)
+	}
+
+	logger.health.SetDegraded("磁盘压力")
+	if state := logger.health.Status().State; state != HealthStateDegraded {
+		t.Errorf("期望 degraded, 得到 %s", state)
+	}
+	logger.health.SetDegraded(degradedHeld)
+	logger.health.ClearDegraded(degradedHeld)
+	if status := logger.health.Status(); status.State != HealthStateDegraded || status.Reason != "磁盘压力" {
+		t.Errorf("只应解除对应的受限原因, 得到 %+v", status)
+	}
+	logger.health.ClearDegraded("磁盘压力")
+	if state := logger.health.Status().State; state != HealthStateHealthy {
+		t.Errorf("期望 healthy, 得到 %s", state)
+	}
+}
+
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  strings.Builder
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestHealthChecker_PermissionRetry(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "readonly.log")
+
+	var output lockedBuffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, ".", 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before chmod\n"))
+	time.Sleep(time.Millisecond * 100)
+	writable := logger.openedInfo()
+
+	if err := os.Chmod(logFile, 0o444); err != nil {
+		t.Fatalf("修改日志文件权限失败: %v", err)
+	}
+
+	// 模拟重新打开一直失败: 打开的文件信息始终停留在修改权限之前
+	deadline := time.Now().Add(time.Millisecond * 3500)
+	for time.Now().Before(deadline) {
+		logger.opened.Store(writable)
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	if count := strings.Count(output.String(), "permission changed"); count != 1 {
+		t.Errorf("同一权限只应请求一次重新打开, 得到 %d 次: %s", count, output.String())
+	}
+	if status := logger.health.Status(); status.State != HealthStateDegraded || status.Reason != degradedUnwritable {
+		t.Errorf("文件不可写时应处于受限状态, 得到 %+v", status)
+	}
+
+	// 已经打开的文件仍然可以写入
+	logger.Write([]byte("after chmod\n"))
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+	if content, _ := os.ReadFile(logFile); !strings.Contains(string(content), "after chmod") {
+		t.Errorf("修改权限后应继续写入已打开的文件: %q", content)
+	}
+
+	if err := os.Chmod(logFile, 0o644); err != nil {
+		t.Fatalf("恢复日志文件权限失败: %v", err)
+	}
+	time.Sleep(time.Millisecond * 1500)
+	if state := logger.health.Status().State; state != HealthStateHealthy {
+		t.Errorf("权限恢复后应恢复健康, 得到 %s", state)
+	}
+}
+
+func TestRotateLogger_ReopenKeepsFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "reopen.log")
+	moved := filepath.Join(tmpDir, "moved.log")
+
+	var output lockedBuffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, ".", 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before reopen\n"))
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	// 日志路径变成目录，重新打开一定失败
+	if err := os.Rename(logFile, moved); err != nil {
+		t.Fatalf("移动日志文件失败: %v", err)
+	}
+	if err := os.Mkdir(logFile, defaultDirMode); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := logger.Reopen(); err == nil {
+		t.Fatal("日志路径是目录时重新打开应该失败")
+	}
+
+	logger.Write([]byte("after reopen\n"))
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("重新打开失败后应继续使用原来的文件: %v", err)
+	}
+	if content, _ := os.ReadFile(moved); !strings.Contains(string(content), "after reopen") {
+		t.Errorf("重新打开失败后应写入原来的文件: %q", content)
+	}
+	if state := logger.health.Status().State; state == HealthStateRecovering {
+		t.Errorf("重新打开失败不应进入恢复流程")
+	}
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// holdMarkerFilename 是保全标记文件名，日志目录下存在该文件时暂停删除归档文件
+// 运维人员也可以直接在日志目录下创建该文件来冻结保留策略
+const holdMarkerFilename = ".qlog-hold"
+
+var (
+	ErrNotHeld = errors.New("retention is not held")
+
+	hold               holdState
+	diskPressureHandle atomic.Value
+)
+
+type (
+	holdState struct {
+		lock   sync.Mutex
+		active bool
+		reason string
+		since  time.Time
+		dirs   map[string]PlaceholderType
+	}
+
+	// DiskPressure 描述保全期间被阻止的删除，用于替代静默删除证据
+	DiskPressure struct {
+		File    string          // 日志文件路径
+		Reason  string          // 保全原因
+		Blocked []RetentionItem // 本应被保留策略删除的文件
+		Time    time.Time       // 检测时间
+	}
+)
+
+// Hold 冻结所有日志输出的保留策略，并在各日志目录下写入保全标记文件
+func Hold(reason string) error {
+	hold.lock.Lock()
+	defer hold.lock.Unlock()
+
+	now := time.Now()
+	hold.active = true
+	hold.reason = reason
+	hold.since = now
+	if hold.dirs == nil {
+		hold.dirs = make(map[string]PlaceholderType)
+	}
+
+	content := fmt.Sprintf("reason: %s\nsince: %s\n", reason, now.UTC().Format(timeFormat))
+	var be BatchError
+	for _, l := range rotateLoggers.snapshot() {
+		dir := filepath.Dir(l.filename)
+		if _, ok := hold.dirs[dir]; ok {
+			continue
+		}
+
+		marker := filepath.Join(dir, holdMarkerFilename)
+		if err := os.WriteFile(marker, []byte(content), defaultFileMode); err != nil {
+			be.Add(fmt.Errorf("failed to write hold marker %s: %w", marker, err))
+			continue
+		}
+		hold.dirs[dir] = Placeholder
+	}
+
+	Warnf("retention held, reason: %s", reason)
+	return be.Err()
+}
+
+// Release 解除保全，删除各日志目录下的保全标记文件
+func Release() error {
+	hold.lock.Lock()
+	defer hold.lock.Unlock()
+
+	dirs := make(map[string]PlaceholderType)
+	for dir := range hold.dirs {
+		dirs[dir] = Placeholder
+	}
+	for _, l := range rotateLoggers.snapshot() {
+		dirs[filepath.Dir(l.filename)] = Placeholder
+	}
+
+	wasActive := hold.active
+	var found bool
+	var be BatchError
+	for dir := range dirs {
+		marker := filepath.Join(dir, holdMarkerFilename)
+		err := os.Remove(marker)
+		if err == nil {
+			found = true
+		} else if !os.IsNotExist(err) {
+			be.Add(fmt.Errorf("failed to remove hold marker %s: %w", marker, err))
+		}
+	}
+
+	hold.active = false
+	hold.reason = ""
+	hold.since = time.Time{}
+	hold.dirs = nil
+
+	if !wasActive && !found {
+		return ErrNotHeld
+	}
+
+	Infof("retention hold released")
+	return be.Err()
+}
+
+// IsHeld 返回给定目录的保留策略是否被冻结，通过 Hold 或者目录下存在保全标记文件
+func IsHeld(dir string) bool {
+	if held, _, _ := HoldStatus(); held {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(dir, holdMarkerFilename))
+	return err == nil
+}
+
+// HoldStatus 返回通过 Hold 设置的保全状态
+func HoldStatus() (held bool, reason string, since time.Time) {
+	hold.lock.Lock()
+	defer hold.lock.Unlock()
+	return hold.active, hold.reason, hold.since
+}
+
+// SetDiskPressureHandler 设置保全期间保留策略被阻止时的回调，传入 nil 取消回调
+func SetDiskPressureHandler(fn func(pressure DiskPressure)) {
+	diskPressureHandle.Store(fn)
+}
+
+func reportDiskPressure(pressure DiskPressure) {
+	fn, ok := diskPressureHandle.Load().(func(pressure DiskPressure))
+	if !ok || fn == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			writeError(r)
+		}
+	}()
+	fn(pressure)
+}
+
+// holdReason 返回目录被冻结的原因，标记文件由外部创建时读取文件内容
+func holdReason(dir string) string {
+	if held, reason, _ := HoldStatus(); held {
+		return reason
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, holdMarkerFilename))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
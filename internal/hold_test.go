@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHold_BlocksDeletion(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "hold.log")
+	backups := []string{
+		filepath.Join(tmpDir, "hold-2024-01-01T00.00.00.000000000Z.log"),
+		filepath.Join(tmpDir, "hold-2024-01-02T00.00.00.000000000Z.log"),
+	}
+	for _, f := range backups {
+		writeTestFile(t, f, 10)
+	}
+
+	logger, err := NewLogger(logFile, NewSizeLimitRotateRule(logFile, backupFileDelimiter, 0, 1, 1, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	var pressures []DiskPressure
+	SetDiskPressureHandler(func(pressure DiskPressure) {
+		pressures = append(pressures, pressure)
+	})
+	defer SetDiskPressureHandler(nil)
+
+	if err := Hold("incident-42"); err != nil {
+		t.Fatalf("Hold 失败: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, holdMarkerFilename)); err != nil {
+		t.Errorf("保全标记文件未创建: %v", err)
+	}
+
+	logger.maybeDeleteOutdatedFiles()
+	if _, err := os.Stat(backups[0]); err != nil {
+		t.Errorf("保全期间不应该删除文件: %v", err)
+	}
+	if len(pressures) != 1 || pressures[0].Reason != "incident-42" || len(pressures[0].Blocked) != 1 {
+		t.Errorf("期望报告一次被阻止的删除, 得到 %+v", pressures)
+	}
+
+	if err := Release(); err != nil {
+		t.Fatalf("Release 失败: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, holdMarkerFilename)); !os.IsNotExist(err) {
+		t.Errorf("保全标记文件未删除: %v", err)
+	}
+
+	logger.maybeDeleteOutdatedFiles()
+	if _, err := os.Stat(backups[0]); !os.IsNotExist(err) {
+		t.Errorf("解除保全后文件应该被删除: %v", err)
+	}
+
+	if err := Release(); err != ErrNotHeld {
+		t.Errorf("期望 ErrNotHeld, 得到 %v", err)
+	}
+}
+
+func TestHold_MarkerFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	if IsHeld(tmpDir) {
+		t.Fatal("未保全的目录不应该被冻结")
+	}
+
+	writeTestFile(t, filepath.Join(tmpDir, holdMarkerFilename), 0)
+	if !IsHeld(tmpDir) {
+		t.Error("存在标记文件的目录应该被冻结")
+	}
+}
+
+func TestHold_BlocksSpaceDeletion(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "space.log")
+	backups := []string{logFile + "-2024-01-01", logFile + "-2024-01-02", logFile + "-2024-01-03"}
+	writeTestFile(t, logFile, 400)
+	for _, f := range backups {
+		writeTestFile(t, f, 300)
+	}
+
+	// 400 + 300*3 超过 1000，最旧的两个备份按总大小删除
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false,
+		WithRuleMaxTotalSize(1000)), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	var pressures []DiskPressure
+	SetDiskPressureHandler(func(pressure DiskPressure) {
+		pressures = append(pressures, pressure)
+	})
+	defer SetDiskPressureHandler(nil)
+
+	if err := Hold("audit"); err != nil {
+		t.Fatalf("Hold 失败: %v", err)
+	}
+	defer Release()
+
+	// 使用率不会低于1%，第三次检查升级到删除备份阶段，剩余的备份按磁盘压力删除
+	StartDiskPressureGuard(1, 1, WarnLevel)
+	defer StopDiskPressureGuard()
+	for i := 0; i < 3; i++ {
+		pressureGuard.check()
+	}
+
+	var reasons []string
+	for _, item := range logger.planRetention() {
+		reasons = append(reasons, item.Reason)
+	}
+	if strings.Join(reasons, ",") != "size,size,disk" {
+		t.Fatalf("期望按总大小和磁盘压力删除, 得到 %v", reasons)
+	}
+
+	logger.maybeDeleteOutdatedFiles()
+	for _, f := range backups {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("保全期间不应该因为空间删除文件: %v", err)
+		}
+	}
+	if len(pressures) == 0 || len(pressures[len(pressures)-1].Blocked) != len(backups) {
+		t.Errorf("期望报告被阻止的删除, 得到 %+v", pressures)
+	}
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	hooks    atomic.Value
+	hookLock sync.Mutex
+)
+
+type (
+	// Record 是编码前的一条日志，传给通过 AddHook 添加的钩子
+	// 钩子可以修改 Message 和 Fields，其余字段只读，修改后不影响写入位置
+	Record struct {
+		Time    time.Time      // 记录时间
+		Level   uint32         // 日志级别，访问日志和命名日志流为 AccessLevel
+		Module  string         // 模块名，命名日志流为日志流名称
+		TraceId string         // 链路追踪ID
+		Message string         // 日志消息
+		Fields  map[string]any // 附加字段，按键排序以 key=value 追加到消息后
+	}
+
+	// Hook 在日志编码前调用，返回 false 时丢弃这条日志
+	Hook func(record *Record) (keep bool)
+)
+
+// AddHook 添加一个日志钩子，按添加顺序在每条日志编码前调用，包括 Print/Printf 写入的访问日志和 GetStream 写入的命名日志流
+// WriteRawString 写入的已格式化日志（如子进程的 ELogItem）不经过钩子
+// 钩子在写日志的协程中同步执行，需要支持并发调用，不要在钩子中阻塞或者写日志
+func AddHook(hook Hook) {
+	if hook == nil {
+		return
+	}
+
+	hookLock.Lock()
+	defer hookLock.Unlock()
+
+	current := loadHooks()
+	next := make([]Hook, 0, len(current)+1)
+	next = append(next, current...)
+	next = append(next, hook)
+	hooks.Store(next)
+}
+
+// ResetHooks 移除所有日志钩子
+func ResetHooks() {
+	hookLock.Lock()
+	defer hookLock.Unlock()
+
+	hooks.Store([]Hook(nil))
+}
+
+func loadHooks() []Hook {
+	h, _ := hooks.Load().([]Hook)
+	return h
+}
+
+// runHooks 依次调用日志钩子，返回钩子处理后的消息以及是否保留这条日志
+// 传入的消息已经脱敏，钩子改写后的消息和添加的字段在这里再次脱敏
+func runHooks(level uint32, module, traceId, msg string) (string, bool) {
+	hs := loadHooks()
+	if len(hs) == 0 {
+		return msg, true
+	}
+
+	record := &Record{
+		Time:    time.Now(),
+		Level:   level,
+		Module:  module,
+		TraceId: traceId,
+		Message: msg,
+	}
+	for _, hook := range hs {
+		if !hook(record) {
+			return "", false
+		}
+	}
+
+	if record.Message != msg {
+		record.Message = redact(record.Message)
+	}
+	return record.Message + formatFields(redactFields(record.Fields)), true
+}
+
+// formatFields 将附加字段按键排序格式化为 ` key=value`
+func formatFields(fields map[string]any) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, key := range keys {
+		buf.WriteByte(plainEncodingSep)
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(truncate(sanitize(fmt.Sprint(fields[key]))))
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	buf := captureOutput(t, InfoLevel)
+	defer ResetHooks()
+
+	var seen []Record
+	AddHook(func(record *Record) bool {
+		seen = append(seen, *record)
+		return !strings.Contains(record.Message, "healthz")
+	})
+	AddHook(func(record *Record) bool {
+		record.Message = strings.ToUpper(record.Message)
+		record.Fields = map[string]any{"region": "cn", "attempt": 2}
+		return true
+	})
+
+	logger := WithModuleName("order").WithTraceId("t1")
+	logger.Info("order created")
+	logger.Info("GET /healthz")
+
+	content := buf.String()
+	if !strings.Contains(content, "[order] [t1] ORDER CREATED attempt=2 region=cn") {
+		t.Errorf("钩子修改的消息和字段未写入: %q", content)
+	}
+	if strings.Contains(content, "healthz") {
+		t.Errorf("钩子丢弃的日志不应写入: %q", content)
+	}
+	if len(seen) != 2 || seen[0].Module != "order" || seen[0].TraceId != "t1" || seen[0].Level != InfoLevel {
+		t.Errorf("钩子收到的日志不正确: %+v", seen)
+	}
+
+	// 访问日志同样经过钩子
+	seen = nil
+	buf.Reset()
+	alog := WithModuleName("")
+	alog.Printf("GET %s 200", "/orders")
+	alog.Print("GET /healthz 200")
+	if !strings.Contains(buf.String(), "GET /ORDERS 200 attempt=2 region=cn") || strings.Contains(buf.String(), "healthz") {
+		t.Errorf("访问日志未经过钩子: %q", buf.String())
+	}
+	if len(seen) != 2 || seen[0].Level != AccessLevel {
+		t.Errorf("访问日志的钩子级别不正确: %+v", seen)
+	}
+
+	GetStream("audit").Print("user login")
+	if !strings.Contains(buf.String(), "USER LOGIN attempt=2 region=cn") || seen[2].Module != "audit" {
+		t.Errorf("命名日志流未经过钩子: %q %+v", buf.String(), seen)
+	}
+
+	ResetHooks()
+	buf.Reset()
+	logger.Info("GET /healthz")
+	if !strings.Contains(buf.String(), "GET /healthz") {
+		t.Errorf("移除钩子后应正常写入: %q", buf.String())
+	}
+}
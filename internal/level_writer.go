@@ -0,0 +1,186 @@
+package internal
+
+import "sync/atomic"
+
+// writerFloor 是写入器链中单独设置的最低级别，全局日志级别检查取它与日志级别中较低的一个
+var writerFloor uint32 = DisableLevel
+
+// levelWriter 为写入器链中的单个写入器设置最低日志级别
+// follow 为 true 时跟随全局日志级别，用于没有单独设置级别的写入器
+type levelWriter struct {
+	writer Writer
+	level  uint32
+	follow bool
+}
+
+// AddWriterWithLevel 添加一个只接收不低于 level 的日志的写入器
+// 例如控制台只输出 WAR 及以上：AddWriterWithLevel(NewWriter(os.Stdout), WarnLevel)
+// 没有单独设置级别的写入器继续按全局日志级别过滤
+func AddWriterWithLevel(w Writer, level uint32) {
+	AddWriter(&levelWriter{
+		writer: w,
+		level:  level,
+	})
+}
+
+func (w *levelWriter) threshold() uint32 {
+	if w.follow {
+		return effectiveLevel()
+	}
+	return w.level
+}
+
+func (w *levelWriter) allow(level uint32) bool {
+	return levelAtLeast(level, w.threshold())
+}
+
+func (w *levelWriter) Close() error {
+	return w.writer.Close()
+}
+
+func (w *levelWriter) Trace(v any) {
+	if w.allow(TraceLevel) {
+		w.writer.Trace(v)
+	}
+}
+
+func (w *levelWriter) Debug(v any) {
+	if w.allow(DebugLevel) {
+		w.writer.Debug(v)
+	}
+}
+
+func (w *levelWriter) Info(v any) {
+	if w.allow(InfoLevel) {
+		w.writer.Info(v)
+	}
+}
+
+func (w *levelWriter) Warn(v any) {
+	if w.allow(WarnLevel) {
+		w.writer.Warn(v)
+	}
+}
+
+func (w *levelWriter) Error(v any) {
+	if w.allow(ErrorLevel) {
+		w.writer.Error(v)
+	}
+}
+
+func (w *levelWriter) Panic(v any) {
+	if w.allow(PanicLevel) {
+		w.writer.Panic(v)
+	}
+}
+
+func (w *levelWriter) Fatal(v any) {
+	if w.allow(FatalLevel) {
+		w.writer.Fatal(v)
+	}
+}
+
+func (w *levelWriter) Log(level uint32, v any) {
+	if w.allow(level) {
+		w.writer.Log(level, v)
+	}
+}
+
+func (w *levelWriter) AccessRecord(v any) {
+	w.writer.AccessRecord(v)
+}
+
+// WriteRawString 写入已格式化的日志，按行首的级别标签过滤，没有级别标签时直接写入
+func (w *levelWriter) WriteRawString(v string) {
+	if level, ok := rawLevel(v); ok && !w.allow(level) {
+		return
+	}
+	w.writer.WriteRawString(v)
+}
+
+// writeBuffered 写入链路缓存的日志，这些日志本来就低于全局日志级别，
+// 跟随全局级别的写入器直接写入，单独设置了级别的写入器仍然按级别过滤
+func writeBuffered(w Writer, v string) {
+	switch x := w.(type) {
+	case comboWriter:
+		for _, child := range x.writers {
+			writeBuffered(child, v)
+		}
+	case *levelWriter:
+		if x.follow {
+			x.writer.WriteRawString(v)
+		} else {
+			x.WriteRawString(v)
+		}
+	default:
+		w.WriteRawString(v)
+	}
+}
+
+// writeRouted 将匹配分流规则的日志写入服务日志文件以外的写入器，如控制台和错误日志
+func writeRouted(w Writer, level uint32, v any) {
+	switch x := w.(type) {
+	case comboWriter:
+		for _, child := range x.writers {
+			writeRouted(child, level, v)
+		}
+	case *levelWriter:
+		if x.allow(level) {
+			writeRouted(x.writer, level, v)
+		}
+	case *concreteWriter:
+		if !x.file {
+			writeLevel(x, level, v)
+		}
+	default:
+		writeLevel(w, level, v)
+	}
+}
+
+// followGlobal 为没有单独设置级别的写入器套上跟随全局日志级别的过滤
+func followGlobal(w Writer) Writer {
+	switch v := w.(type) {
+	case *levelWriter:
+		return v
+	case comboWriter:
+		writers := make([]Writer, 0, len(v.writers))
+		for _, child := range v.writers {
+			writers = append(writers, followGlobal(child))
+		}
+		return comboWriter{writers: writers}
+	default:
+		return &levelWriter{
+			writer: w,
+			follow: true,
+		}
+	}
+}
+
+// lowestWriterLevel 返回写入器链中单独设置的最低级别，没有设置时返回 DisableLevel
+func lowestWriterLevel(w Writer) uint32 {
+	var lowest uint32 = DisableLevel
+	switch v := w.(type) {
+	case *levelWriter:
+		if !v.follow {
+			lowest = v.level
+		}
+	case comboWriter:
+		for _, child := range v.writers {
+			if level := lowestWriterLevel(child); lowest == DisableLevel ||
+				(level != DisableLevel && levelPriority(level) < levelPriority(lowest)) {
+				lowest = level
+			}
+		}
+	}
+
+	return lowest
+}
+
+func updateWriterFloor(w Writer) {
+	if w == nil {
+		atomic.StoreUint32(&writerFloor, DisableLevel)
+		return
+	}
+
+	atomic.StoreUint32(&writerFloor, lowestWriterLevel(w))
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddWriterWithLevel(t *testing.T) {
+	file := captureOutput(t, InfoLevel)
+	var console, ring bytes.Buffer
+	AddWriterWithLevel(NewWriter(&console), WarnLevel)
+	AddWriterWithLevel(NewWriter(&ring), TraceLevel)
+
+	if !shallLog(TraceLevel) {
+		t.Fatal("全局检查应该按写入器中最低的级别")
+	}
+
+	logger := WithModuleName("level")
+	logger.Trace("trace message")
+	logger.Info("info message")
+	logger.Warn("warn message")
+
+	check := func(name string, buf bytes.Buffer, want, notWant []string) {
+		for _, w := range want {
+			if !strings.Contains(buf.String(), w) {
+				t.Errorf("%s 应该包含 %q, 得到 %q", name, w, buf.String())
+			}
+		}
+		for _, w := range notWant {
+			if strings.Contains(buf.String(), w) {
+				t.Errorf("%s 不应该包含 %q, 得到 %q", name, w, buf.String())
+			}
+		}
+	}
+	check("文件", *file, []string{"info message", "warn message"}, []string{"trace message"})
+	check("控制台", console, []string{"warn message"}, []string{"trace message", "info message"})
+	check("缓冲区", ring, []string{"trace message", "info message", "warn message"}, nil)
+
+	// 子进程日志按行首的级别标签过滤
+	file.Reset()
+	console.Reset()
+	ring.Reset()
+	logger.WriteRawString(GetOutputStringFormatted(LevelDebug, "child debug"))
+	logger.WriteRawString(GetOutputStringFormatted(LevelError, "child error"))
+	check("文件", *file, []string{"child error"}, []string{"child debug"})
+	check("控制台", console, []string{"child error"}, []string{"child debug"})
+	check("缓冲区", ring, []string{"child debug", "child error"}, nil)
+
+	// 链路出错时写入的缓存日志不进入级别更高的控制台
+	EnableTraceBuffer(10, 10, time.Minute)
+	defer DisableTraceBuffer()
+	file.Reset()
+	console.Reset()
+	traced := logger.WithTraceId("t1")
+	traced.Debug("traced debug")
+	traced.Error("traced error")
+	check("文件", *file, []string{"traced debug", "traced error"}, nil)
+	check("控制台", console, []string{"traced error"}, []string{"traced debug"})
+
+	Reset()
+	if shallLog(TraceLevel) {
+		t.Error("移除写入器后应该恢复按全局日志级别检查")
+	}
+}
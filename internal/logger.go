@@ -27,6 +27,18 @@ type Logger interface {
 	Error(...any)
 	// Errorf logs a message at error level.
 	Errorf(string, ...any)
+	// Panic logs a message at panic level, flushes log files and then panics.
+	Panic(...any)
+	// Panicf logs a message at panic level, flushes log files and then panics.
+	Panicf(string, ...any)
+	// Fatal logs a message at fatal level, flushes log files and then exits the process.
+	Fatal(...any)
+	// Fatalf logs a message at fatal level, flushes log files and then exits the process.
+	Fatalf(string, ...any)
+	// Log logs a message at the given level, including levels registered by RegisterLevel.
+	Log(uint32, ...any)
+	// Logf logs a message at the given level, including levels registered by RegisterLevel.
+	Logf(uint32, string, ...any)
 
 	// WriteRawString writes a raw message with module.
 	WriteRawString(string)
@@ -3,10 +3,12 @@ package internal
 import (
 	"fmt"
 	"io"
+	"path"
 	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 var (
@@ -23,11 +25,19 @@ type (
 	LogOption func(options *logOptions)
 
 	logOptions struct {
-		gzipEnabled  bool
-		keepDays     int
-		maxBackups   int
-		maxSize      int
-		rotationRule string
+		gzipEnabled         bool
+		keepDays            int
+		maxBackups          int
+		maxSize             int
+		rotationRule        string
+		compressConcurrency int
+		compressRateLimit   int64
+		maxTotalSize        int
+		diskThreshold       int
+		maxAge              time.Duration
+		fallbackDir         string
+		fallbackStderr      bool
+		fallbackBufferSize  int
 	}
 )
 
@@ -43,93 +53,107 @@ func AddWriter(w Writer) {
 		// no need to check if the existing writer is a comboWriter,
 		// because it is not common to add more than one writer.
 		// even more than one writer, the behavior is the same.
-		SetWriter(comboWriter{
+		combo := comboWriter{
 			writers: []Writer{ow, w},
-		})
+		}
+		// 有写入器单独设置了更低的级别时，其他写入器需要按全局日志级别过滤
+		if lowestWriterLevel(combo) != DisableLevel {
+			combo = followGlobal(combo).(comboWriter)
+		}
+		SetWriter(combo)
 	}
 }
 
 // Close 关闭日志系统
 func Close() error {
+	StopWatchSignals()
+	StopDiskPressureGuard()
+	DisableTraceBuffer()
+
+	updateWriterFloor(nil)
+	var be BatchError
+	be.Add(resetRoutes())
+	be.Add(resetStreams())
 	if w := writer.Swap(nil); w != nil {
-		return w.(io.Closer).Close()
+		be.Add(w.(io.Closer).Close())
 	}
 
-	return nil
+	return be.Err()
 }
 
 // Trace 将参数写入调试日志
 func Trace(v ...any) {
 	if shallLog(TraceLevel) {
-		getWriter().Debug(fmt.Sprint(v...))
+		getWriter().Debug(escapeMessage(fmt.Sprint(v...)))
 	}
 }
 
 // Tracef 将参数写入调试日志
 func Tracef(format string, v ...any) {
 	if shallLog(TraceLevel) {
-		getWriter().Debug(fmt.Sprintf(format, v...))
+		getWriter().Debug(escapeMessage(fmt.Sprintf(format, v...)))
 	}
 }
 
 // Debug 将参数写入调试日志
 func Debug(v ...any) {
 	if shallLog(DebugLevel) {
-		getWriter().Debug(fmt.Sprint(v...))
+		getWriter().Debug(escapeMessage(fmt.Sprint(v...)))
 	}
 }
 
 // Debugf 将参数写入调试日志
 func Debugf(format string, v ...any) {
 	if shallLog(DebugLevel) {
-		getWriter().Debug(fmt.Sprintf(format, v...))
+		getWriter().Debug(escapeMessage(fmt.Sprintf(format, v...)))
 	}
 }
 
 // Error 将参数写入错误日志
 func Error(v ...any) {
 	if shallLog(ErrorLevel) {
-		getWriter().Error(fmt.Sprint(v...))
+		getWriter().Error(escapeMessage(fmt.Sprint(v...)))
 	}
 }
 
 // Errorf 将参数写入错误日志
 func Errorf(format string, v ...any) {
 	if shallLog(ErrorLevel) {
-		getWriter().Error(fmt.Errorf(format, v...).Error())
+		getWriter().Error(escapeMessage(fmt.Errorf(format, v...).Error()))
 	}
 }
 
 // Warn 将参数写入警告日志
 func Warn(v ...any) {
 	if shallLog(WarnLevel) {
-		getWriter().Warn(fmt.Sprint(v...))
+		getWriter().Warn(escapeMessage(fmt.Sprint(v...)))
 	}
 }
 
 // Warnf 将参数写入警告日志
 func Warnf(format string, v ...any) {
 	if shallLog(WarnLevel) {
-		getWriter().Warn(fmt.Errorf(format, v...).Error())
+		getWriter().Warn(escapeMessage(fmt.Errorf(format, v...).Error()))
 	}
 }
 
 // Info 将参数写入访问日志
 func Info(v ...any) {
 	if shallLog(InfoLevel) {
-		getWriter().Info(fmt.Sprint(v...))
+		getWriter().Info(escapeMessage(fmt.Sprint(v...)))
 	}
 }
 
 // Infof 将参数写入访问日志
 func Infof(format string, v ...any) {
 	if shallLog(InfoLevel) {
-		getWriter().Info(fmt.Sprintf(format, v...))
+		getWriter().Info(escapeMessage(fmt.Sprintf(format, v...)))
 	}
 }
 
 // Reset 清除写入器并重置日志级别
 func Reset() Writer {
+	updateWriterFloor(nil)
 	return writer.Swap(nil)
 }
 
@@ -147,6 +171,7 @@ func GetLevel() uint32 {
 func SetWriter(w Writer) {
 	if atomic.LoadUint32(&logLevel) != DisableLevel {
 		writer.Store(w)
+		updateWriterFloor(w)
 	}
 }
 
@@ -160,6 +185,15 @@ func SetUp(c LogConf) (err error) {
 		setupLogLevel(c)
 
 		atomic.StoreUint32(&maxContentLength, c.MaxContentLength)
+		setupRecentRing(c)
+		setupSanitize(c)
+		if err = SetRedaction(c.Redact, c.RedactPatterns, c.RedactFields); err != nil {
+			return
+		}
+		if level, ok := parseLevel(c.StackLevel); ok {
+			SetStackLevel(level)
+		}
+		EnableTraceBuffer(c.TraceBufferRecords, c.TraceBufferTraces, c.TraceBufferTimeout)
 
 		switch c.Mode {
 		case fileMode:
@@ -179,6 +213,23 @@ func WithKeepDays(days int) LogOption {
 	}
 }
 
+// WithMaxAge 自定义日志文件最长保留时长，按文件修改时间判断，设置后优先于保留天数
+func WithMaxAge(age time.Duration) LogOption {
+	return func(opts *logOptions) {
+		opts.maxAge = age
+	}
+}
+
+// WithFallbackOutput 自定义主日志目录故障时的备用输出
+// dir 为备用目录，stderr 表示是否输出到标准错误，bufferSize 为内存中缓存用于恢复后回放的日志条数
+func WithFallbackOutput(dir string, stderr bool, bufferSize int) LogOption {
+	return func(opts *logOptions) {
+		opts.fallbackDir = dir
+		opts.fallbackStderr = stderr
+		opts.fallbackBufferSize = bufferSize
+	}
+}
+
 // WithGzip 自定义日志文件自动使用 gzip 压缩
 func WithGzip() LogOption {
 	return func(opts *logOptions) {
@@ -186,6 +237,13 @@ func WithGzip() LogOption {
 	}
 }
 
+// WithGzipEnabled 自定义日志文件是否使用 gzip 压缩，用于单个日志流覆盖全局配置
+func WithGzipEnabled(enabled bool) LogOption {
+	return func(opts *logOptions) {
+		opts.gzipEnabled = enabled
+	}
+}
+
 // WithMaxBackups 自定义保留的日志文件备份数量
 func WithMaxBackups(count int) LogOption {
 	return func(opts *logOptions) {
@@ -200,6 +258,34 @@ func WithMaxSize(size int) LogOption {
 	}
 }
 
+// WithMaxTotalSize 自定义单个日志流（备份文件与当前文件）占用的最大空间（MB）
+func WithMaxTotalSize(size int) LogOption {
+	return func(opts *logOptions) {
+		opts.maxTotalSize = size
+	}
+}
+
+// WithDiskThreshold 自定义归档文件占用磁盘容量的百分比阈值
+func WithDiskThreshold(percent int) LogOption {
+	return func(opts *logOptions) {
+		opts.diskThreshold = percent
+	}
+}
+
+// WithCompressConcurrency 自定义压缩工作池的最大并发数，所有日志文件共享
+func WithCompressConcurrency(n int) LogOption {
+	return func(opts *logOptions) {
+		opts.compressConcurrency = n
+	}
+}
+
+// WithCompressRateLimit 自定义压缩时读取文件的速率上限（字节/秒），0表示不限速
+func WithCompressRateLimit(bytesPerSecond int64) LogOption {
+	return func(opts *logOptions) {
+		opts.compressRateLimit = bytesPerSecond
+	}
+}
+
 // WithRotation 自定义使用的日志轮转规则
 func WithRotation(r string) LogOption {
 	return func(opts *logOptions) {
@@ -207,21 +293,34 @@ func WithRotation(r string) LogOption {
 	}
 }
 
-func createOutput(path string) (io.WriteCloser, error) {
+// createOutput 创建一个轮转日志输出，opts 在全局配置的基础上覆盖当前日志流的配置
+func createOutput(path string, opts ...LogOption) (io.WriteCloser, error) {
 	if len(path) == 0 {
 		return nil, ErrLogPathNotSet
 	}
 
+	o := options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ruleOpts := []RuleOption{
+		WithRuleMaxTotalSize(int64(o.maxTotalSize) * megaBytes),
+		WithRuleDiskThreshold(o.diskThreshold),
+		WithRuleMaxAge(o.maxAge),
+	}
+
 	var rule RotateRule
-	switch options.rotationRule {
+	switch o.rotationRule {
 	case sizeRotationRule:
-		rule = NewSizeLimitRotateRule(path, backupFileDelimiter, options.keepDays, options.maxSize,
-			options.maxBackups, options.gzipEnabled)
+		rule = NewSizeLimitRotateRule(path, backupFileDelimiter, o.keepDays, o.maxSize,
+			o.maxBackups, o.gzipEnabled, ruleOpts...)
 	default:
-		rule = DefaultRotateRule(path, backupFileDelimiter, options.keepDays, options.gzipEnabled)
+		rule = DefaultRotateRule(path, backupFileDelimiter, o.keepDays, o.gzipEnabled, ruleOpts...)
 	}
 
-	return NewLogger(path, rule, options.gzipEnabled)
+	return NewLogger(path, rule, o.gzipEnabled,
+		WithFallback(o.fallbackDir, o.fallbackStderr, o.fallbackBufferSize))
 }
 
 func encodeError(err error) (ret string) {
@@ -266,19 +365,55 @@ func handleOptions(opts []LogOption) {
 }
 
 func setupLogLevel(c LogConf) {
-	switch strings.ToUpper(c.Level) {
+	if level, ok := parseLevel(c.Level); ok {
+		SetLevel(level)
+	}
+}
+
+// parseLevel 将日志级别字符串转换为数值
+func parseLevel(level string) (uint32, bool) {
+	switch strings.ToUpper(level) {
 	case LevelTrace:
-		SetLevel(TraceLevel)
+		return TraceLevel, true
 	case LevelDebug:
-		SetLevel(DebugLevel)
+		return DebugLevel, true
 	case LevelInfo:
-		SetLevel(InfoLevel)
+		return InfoLevel, true
 	case LevelWarn:
-		SetLevel(WarnLevel)
+		return WarnLevel, true
 	case LevelError:
-		SetLevel(ErrorLevel)
+		return ErrorLevel, true
+	case LevelPanic:
+		return PanicLevel, true
+	case LevelFatal:
+		return FatalLevel, true
 	case LevelDisable:
-		SetLevel(DisableLevel)
+		return DisableLevel, true
+	default:
+		return customLevelByTag(strings.ToUpper(level))
+	}
+}
+
+func setupRecentRing(c LogConf) {
+	if c.RecentRecords <= 0 && c.RecentBytes <= 0 {
+		return
+	}
+
+	level, ok := parseLevel(c.RecentLevel)
+	if !ok {
+		level = TraceLevel
+	}
+	SetRecentRing(NewRingWriter(c.RecentRecords, c.RecentBytes), level)
+}
+
+func setupSanitize(c LogConf) {
+	switch c.Sanitize {
+	case sanitizeMultilineMode:
+		SetSanitizeMode(SanitizeMultiline, c.StripAnsi)
+	case sanitizeOffMode:
+		SetSanitizeMode(SanitizeOff, c.StripAnsi)
+	default:
+		SetSanitizeMode(SanitizeEscape, c.StripAnsi)
 	}
 }
 
@@ -297,11 +432,55 @@ func setupWithFiles(c LogConf) error {
 	}
 
 	SetWriter(w)
+	if c.ErrorLog {
+		errorFile := path.Join(c.ServerLogDir, c.ServiceName+"_"+errorFilename)
+		out, err := createOutput(errorFile, c.ErrorStream.options()...)
+		if err != nil {
+			return err
+		}
+		AddWriter(newErrorWriter(out))
+	}
+	if err = setupRoutes(c); err != nil {
+		return err
+	}
+	setupStreams(c)
+	if c.HandleSignals {
+		WatchSignals()
+	}
+	if c.CrashLog {
+		crashFile := path.Join(c.ServerLogDir, c.ServiceName+"_"+crashFilename)
+		if err := SetCrashOutput(crashFile); err != nil {
+			Errorf("failed to set crash output: %s, error: %v", crashFile, err)
+		}
+	}
+	if c.DiskHighWatermark > 0 {
+		level, ok := parseLevel(c.DiskPressureLevel)
+		if !ok {
+			level = InfoLevel
+		}
+		StartDiskPressureGuard(c.DiskHighWatermark, c.DiskLowWatermark, level)
+	}
 	return nil
 }
 
+// shallLog 返回日志是否需要写入写入器链，写入器单独设置了更低的级别时按最低的级别判断，
+// 具体写入哪些写入器由各自的级别决定
 func shallLog(level uint32) bool {
-	return atomic.LoadUint32(&logLevel) <= level
+	return levelAtLeast(level, effectiveLevel()) || levelAtLeast(level, atomic.LoadUint32(&writerFloor))
+}
+
+// ShallLog 返回给定级别的日志是否需要输出，考虑磁盘压力时提升的级别
+func ShallLog(level uint32) bool {
+	return shallLog(level)
+}
+
+// effectiveLevel 返回实际生效的日志级别，磁盘压力保护时不低于 levelFloor
+func effectiveLevel() uint32 {
+	level := atomic.LoadUint32(&logLevel)
+	if floor := atomic.LoadUint32(&levelFloor); levelPriority(floor) > levelPriority(level) {
+		return floor
+	}
+	return level
 }
 
 func writeError(val any) {
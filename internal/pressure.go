@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// pressureNormal 表示磁盘空间正常
+	pressureNormal = iota
+	// pressureRaiseLevel 表示已提升有效日志级别，丢弃低级别日志
+	pressureRaiseLevel
+	// pressurePauseCompress 表示已暂停压缩
+	pressurePauseCompress
+	// pressureDeleteBackups 表示允许删除最旧的备份释放空间
+	pressureDeleteBackups
+
+	pressureCheckInterval = 10 * time.Second
+)
+
+var (
+	// levelFloor 是磁盘压力时提升后的最低日志级别，0表示未提升
+	levelFloor    uint32
+	pressureStage int32
+	pressureGuard diskPressureGuard
+)
+
+// diskPressureGuard 定期检查日志目录所在磁盘的使用率，按高低水位逐级进入或退出保护模式
+type diskPressureGuard struct {
+	lock  sync.Mutex
+	high  int
+	low   int
+	level uint32
+	stop  chan PlaceholderType
+}
+
+// StartDiskPressureGuard 启动磁盘压力保护，high/low 为磁盘使用率的高低水位百分比
+// 超过高水位后每个检查周期升级一步：提升日志级别到 level、暂停压缩、删除最旧的备份；
+// 低于低水位后恢复正常
+func StartDiskPressureGuard(high, low int, level uint32) {
+	pressureGuard.lock.Lock()
+	defer pressureGuard.lock.Unlock()
+
+	if pressureGuard.stop != nil || high <= 0 {
+		return
+	}
+	if low <= 0 || low >= high {
+		low = high
+	}
+
+	pressureGuard.high = high
+	pressureGuard.low = low
+	pressureGuard.level = level
+	pressureGuard.stop = make(chan PlaceholderType)
+
+	go pressureGuard.run(pressureGuard.stop)
+}
+
+// StopDiskPressureGuard 停止磁盘压力保护并恢复正常状态
+func StopDiskPressureGuard() {
+	pressureGuard.lock.Lock()
+	if pressureGuard.stop == nil {
+		pressureGuard.lock.Unlock()
+		return
+	}
+
+	close(pressureGuard.stop)
+	pressureGuard.stop = nil
+	pressureGuard.lock.Unlock()
+
+	// 健康状态的订阅者可能调用 qlog 的接口，不能在持有锁时通知
+	pressureGuard.setStage(pressureNormal)
+}
+
+func (g *diskPressureGuard) run(stop chan PlaceholderType) {
+	ticker := time.NewTicker(pressureCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.check()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (g *diskPressureGuard) check() {
+	usage, ok := maxDiskUsage()
+	if !ok {
+		return
+	}
+
+	stage := currentPressureStage()
+	switch {
+	case usage >= g.high:
+		if stage < pressureDeleteBackups {
+			stage++
+			Warnf("disk usage %d%% above high watermark %d%%, enter protective stage %d: %s",
+				usage, g.high, stage, pressureStageDesc(stage))
+			g.setStage(stage)
+		}
+		if stage == pressureDeleteBackups {
+			for _, l := range rotateLoggers.snapshot() {
+				l.maybeDeleteOutdatedFiles()
+			}
+		}
+	case usage < g.low && stage != pressureNormal:
+		g.setStage(pressureNormal)
+		Warnf("disk usage %d%% below low watermark %d%%, leave protective mode", usage, g.low)
+	}
+}
+
+// setStage 切换保护阶段并更新所有文件输出的受限状态，会通知健康状态的订阅者，调用时不能持有 g.lock
+func (g *diskPressureGuard) setStage(stage int32) {
+	atomic.StoreInt32(&pressureStage, stage)
+
+	if stage >= pressureRaiseLevel {
+		atomic.StoreUint32(&levelFloor, g.level)
+	} else {
+		atomic.StoreUint32(&levelFloor, 0)
+	}
+	compressor.setPaused(stage >= pressurePauseCompress)
+
+	for _, l := range rotateLoggers.snapshot() {
+		if stage > pressureNormal {
+			l.health.SetDegraded(degradedPressure)
+		} else {
+			l.health.ClearDegraded(degradedPressure)
+		}
+	}
+}
+
+func currentPressureStage() int32 {
+	return atomic.LoadInt32(&pressureStage)
+}
+
+// pressureDeletionAllowed 返回是否允许因磁盘压力删除备份
+// 未启用保护模式时保持原有行为，启用后只有升级到最后一步才允许删除
+func pressureDeletionAllowed() bool {
+	pressureGuard.lock.Lock()
+	enabled := pressureGuard.stop != nil
+	pressureGuard.lock.Unlock()
+
+	return !enabled || currentPressureStage() >= pressureDeleteBackups
+}
+
+func pressureStageDesc(stage int32) string {
+	switch stage {
+	case pressureRaiseLevel:
+		return "raise log level"
+	case pressurePauseCompress:
+		return "pause compression"
+	case pressureDeleteBackups:
+		return "delete oldest backups"
+	default:
+		return "normal"
+	}
+}
+
+// maxDiskUsage 返回所有日志目录所在磁盘中最高的使用率百分比
+func maxDiskUsage() (int, bool) {
+	var usage int
+	var ok bool
+	checked := make(map[string]PlaceholderType)
+	for _, l := range rotateLoggers.snapshot() {
+		dir := filepath.Dir(l.filename)
+		if _, exists := checked[dir]; exists {
+			continue
+		}
+		checked[dir] = Placeholder
+
+		total, err := GetDirOnDiskTotalSize(dir)
+		if err != nil || total <= 0 {
+			continue
+		}
+		free, err := GetDirOnDiskFreeSize(dir)
+		if err != nil {
+			continue
+		}
+
+		if u := int((total - free) * 100 / total); u > usage || !ok {
+			usage = u
+			ok = true
+		}
+	}
+
+	return usage, ok
+}
+
+// markPressure 最后一步保护时，标记一个最旧的备份用于释放空间
+func (r *DailyRotateRule) markPressure(files []string, outdated map[string]string) {
+	if currentPressureStage() < pressureDeleteBackups {
+		return
+	}
+
+	for _, f := range files {
+		if _, exists := outdated[f]; !exists {
+			outdated[f] = RetentionReasonDisk
+			return
+		}
+	}
+}
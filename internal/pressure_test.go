@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskPressureGuard_Stages(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "pressure.log")
+	backup := logFile + backupFileDelimiter + "2024-01-01"
+	writeTestFile(t, backup, 10)
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	oldLevel := GetLevel()
+	SetLevel(DebugLevel)
+	defer SetLevel(oldLevel)
+
+	// 使用率不会低于1%，每次检查都会升级一步
+	StartDiskPressureGuard(1, 1, WarnLevel)
+	defer StopDiskPressureGuard()
+
+	if pressureDeletionAllowed() {
+		t.Fatal("开启保护后不应该允许因磁盘压力删除")
+	}
+
+	pressureGuard.check()
+	if shallLog(InfoLevel) || !shallLog(WarnLevel) {
+		t.Errorf("第一步应该提升日志级别到 WAR")
+	}
+	if GetLevel() != DebugLevel {
+		t.Errorf("不应该修改配置的日志级别, 得到 %d", GetLevel())
+	}
+	if status := logger.health.Status(); status.State != HealthStateDegraded || status.Reason != degradedPressure {
+		t.Errorf("保护模式下应该处于受限状态, 得到 %+v", status)
+	}
+
+	pressureGuard.check()
+	compressor.lock.Lock()
+	paused := compressor.paused
+	compressor.lock.Unlock()
+	if !paused {
+		t.Errorf("第二步应该暂停压缩")
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("删除备份前不应该删除文件: %v", err)
+	}
+
+	pressureGuard.check()
+	if !pressureDeletionAllowed() {
+		t.Errorf("第三步应该允许删除备份")
+	}
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		t.Errorf("最旧的备份应该被删除: %v", err)
+	}
+	if state := logger.health.Status().State; state != HealthStateDegraded {
+		t.Errorf("清理过期文件不应该解除磁盘压力的受限状态, 得到 %s", state)
+	}
+
+	// 低于低水位后恢复
+	pressureGuard.high = 101
+	pressureGuard.low = 101
+	pressureGuard.check()
+	if currentPressureStage() != pressureNormal || !shallLog(DebugLevel) {
+		t.Errorf("低于低水位后应该恢复正常")
+	}
+	compressor.lock.Lock()
+	paused = compressor.paused
+	compressor.lock.Unlock()
+	if paused {
+		t.Errorf("恢复后应该继续压缩")
+	}
+	if state := logger.health.Status().State; state != HealthStateHealthy {
+		t.Errorf("退出保护模式后应该恢复健康, 得到 %s", state)
+	}
+}
+
+func TestDiskPressureGuard_StopNotifiesWithoutLock(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "notify.log")
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	StartDiskPressureGuard(1, 1, WarnLevel)
+	pressureGuard.check()
+
+	// 订阅者在通知中查询保护状态，持有锁通知时会死锁
+	cancel := SubscribeHealth(func(event HealthEvent) {
+		pressureDeletionAllowed()
+	})
+	defer cancel()
+
+	stopped := make(chan PlaceholderType)
+	go func() {
+		StopDiskPressureGuard()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("停止磁盘压力保护时通知订阅者不应该死锁")
+	}
+	if state := logger.health.Status().State; state != HealthStateHealthy {
+		t.Errorf("停止保护后应该恢复健康, 得到 %s", state)
+	}
+}
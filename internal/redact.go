@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+const redactMask = "******"
+
+var (
+	// redaction 保存当前生效的脱敏规则，为空时不脱敏
+	redaction atomic.Value
+
+	cardPattern   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	bearerPattern = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern  = regexp.MustCompile(`(?:\+86[- ]?)?\b1[3-9]\d{9}\b|\+[1-9]\d{7,14}\b`)
+
+	// builtinSecretKeys 是内置脱敏的键名，消息中 key=value 或 "key":"value" 的值被替换
+	builtinSecretKeys = []string{"password", "passwd", "pwd", "secret"}
+)
+
+type (
+	redactor struct {
+		rules  []redactRule
+		fields map[string]struct{}
+	}
+
+	redactRule struct {
+		pattern *regexp.Regexp
+		replace func(match string) string
+	}
+)
+
+// SetRedaction 设置日志脱敏规则，作用于消息、钩子添加的字段、访问日志和子进程日志
+// builtin 开启内置的银行卡号（通过 Luhn 校验）、邮箱、手机号、Bearer 令牌和 password 等键值的脱敏，
+// patterns 为自定义的正则，匹配的内容替换为 ******，fields 为需要脱敏的字段名，
+// 字段以及消息中同名 key=value 的值被替换，三者都为空时关闭脱敏
+func SetRedaction(builtin bool, patterns, fields []string) error {
+	if !builtin && len(patterns) == 0 && len(fields) == 0 {
+		redaction.Store((*redactor)(nil))
+		return nil
+	}
+
+	r := &redactor{
+		fields: make(map[string]struct{}, len(fields)),
+	}
+
+	var keys []string
+	if builtin {
+		keys = append(keys, builtinSecretKeys...)
+	}
+	for _, field := range fields {
+		r.fields[strings.ToLower(field)] = struct{}{}
+		keys = append(keys, regexp.QuoteMeta(field))
+	}
+	if len(keys) > 0 {
+		r.rules = append(r.rules, keyValueRules(keys)...)
+	}
+
+	if builtin {
+		r.rules = append(r.rules,
+			redactRule{pattern: bearerPattern, replace: func(match string) string {
+				return bearerPattern.ReplaceAllString(match, "${1}"+redactMask)
+			}},
+			redactRule{pattern: cardPattern, replace: maskCard},
+			redactRule{pattern: emailPattern},
+			redactRule{pattern: phonePattern},
+		)
+	}
+
+	for _, p := range patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid redact pattern: %s, error: %w", p, err)
+		}
+		r.rules = append(r.rules, redactRule{pattern: pattern})
+	}
+
+	redaction.Store(r)
+	return nil
+}
+
+// keyValueRules 返回替换消息中 "key":"value" 和 key=value 的值的规则，键名不区分大小写
+func keyValueRules(keys []string) []redactRule {
+	names := strings.Join(keys, "|")
+	quoted := regexp.MustCompile(`(?i)("?\b(?:` + names + `)\b"?\s*[=:]\s*")[^"]*(")`)
+	plain := regexp.MustCompile(`(?i)(\b(?:` + names + `)\b\s*[=:]\s*)[^\s"&,;]+`)
+
+	return []redactRule{
+		{pattern: quoted, replace: func(match string) string {
+			return quoted.ReplaceAllString(match, "${1}"+redactMask+"${2}")
+		}},
+		{pattern: plain, replace: func(match string) string {
+			return plain.ReplaceAllString(match, "${1}"+redactMask)
+		}},
+	}
+}
+
+// maskCard 只替换通过 Luhn 校验的数字串，避免误伤普通的长数字
+func maskCard(match string) string {
+	if !luhnValid(match) {
+		return match
+	}
+	return redactMask
+}
+
+func luhnValid(s string) bool {
+	var sum, count int
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		count++
+	}
+
+	return count >= 13 && sum%10 == 0
+}
+
+func loadRedactor() *redactor {
+	r, _ := redaction.Load().(*redactor)
+	return r
+}
+
+// redact 按当前的脱敏规则替换消息中的敏感内容
+func redact(msg string) string {
+	r := loadRedactor()
+	if r == nil {
+		return msg
+	}
+
+	for _, rule := range r.rules {
+		if rule.replace != nil {
+			msg = rule.pattern.ReplaceAllStringFunc(msg, rule.replace)
+		} else {
+			msg = rule.pattern.ReplaceAllLiteralString(msg, redactMask)
+		}
+	}
+
+	return msg
+}
+
+// redactFields 返回替换了敏感字段值的字段，不修改传入的 map，钩子可能在多条日志间共享同一个 map
+func redactFields(fields map[string]any) map[string]any {
+	r := loadRedactor()
+	if r == nil || len(r.fields) == 0 {
+		return fields
+	}
+
+	var redacted map[string]any
+	for key := range fields {
+		if _, ok := r.fields[strings.ToLower(key)]; !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(map[string]any, len(fields))
+			for k, v := range fields {
+				redacted[k] = v
+			}
+		}
+		redacted[key] = redactMask
+	}
+	if redacted == nil {
+		return fields
+	}
+
+	return redacted
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	if err := SetRedaction(true, []string{`order-\d+`}, []string{"idCard"}); err != nil {
+		t.Fatalf("设置脱敏规则失败: %v", err)
+	}
+	defer SetRedaction(false, nil, nil)
+
+	tests := []struct {
+		input  string
+		want   string
+		secret string
+	}{
+		{"card 4111 1111 1111 1111 paid", "card ****** paid", "4111"},
+		{"id 1234567890123456 kept", "id 1234567890123456 kept", ""},
+		{"mail alice@example.com", "mail ******", "alice"},
+		{"phone 13812345678", "phone ******", "13812345678"},
+		{"Authorization: Bearer eyJhbGciOi.abc-def", "Authorization: Bearer ******", "eyJ"},
+		{"user=bob password=s3cret&next=1", "user=bob password=******&next=1", "s3cret"},
+		{`{"password":"s3cret","user":"bob"}`, `{"password":"******","user":"bob"}`, "s3cret"},
+		{"IDCARD=110101199001011234 ok", "IDCARD=****** ok", "110101"},
+		{"created order-42", "created ******", "order-42"},
+	}
+
+	for _, test := range tests {
+		got := redact(test.input)
+		if got != test.want {
+			t.Errorf("redact(%q) = %q, want %q", test.input, got, test.want)
+		}
+		if len(test.secret) > 0 && strings.Contains(got, test.secret) {
+			t.Errorf("redact(%q) 未脱敏 %q", test.input, test.secret)
+		}
+	}
+
+	if err := SetRedaction(false, []string{"("}, nil); err == nil {
+		t.Error("非法正则应返回错误")
+	}
+}
+
+func TestRedactRecords(t *testing.T) {
+	buf := captureOutput(t, InfoLevel)
+
+	if err := SetRedaction(true, nil, []string{"token"}); err != nil {
+		t.Fatalf("设置脱敏规则失败: %v", err)
+	}
+	defer SetRedaction(false, nil, nil)
+	AddHook(func(record *Record) bool {
+		record.Fields = map[string]any{"token": "abc123", "user": "bob"}
+		return true
+	})
+	defer ResetHooks()
+
+	logger := WithModuleName("login")
+	logger.Infof("login request: %s", "user=bob password=hunter2")
+	logger.Printf("POST /login email=bob@example.com")
+	logger.WriteRawString(GetOutputStringFormatted(LevelInfo, "child password=hunter2"))
+
+	content := buf.String()
+	for _, secret := range []string{"hunter2", "abc123", "bob@example.com"} {
+		if strings.Contains(content, secret) {
+			t.Errorf("日志中包含未脱敏的内容 %q: %q", secret, content)
+		}
+	}
+	if !strings.Contains(content, "token=****** user=bob") {
+		t.Errorf("字段未按名称脱敏: %q", content)
+	}
+}
+
+func TestRedactBeforeHooks(t *testing.T) {
+	buf := captureOutput(t, InfoLevel)
+
+	if err := SetRedaction(true, nil, []string{"token"}); err != nil {
+		t.Fatalf("设置脱敏规则失败: %v", err)
+	}
+	defer SetRedaction(false, nil, nil)
+
+	// 钩子在多条日志间共享同一个 map
+	shared := map[string]any{"token": "abc123"}
+	var seen []string
+	AddHook(func(record *Record) bool {
+		seen = append(seen, record.Message)
+		record.Fields = shared
+		return true
+	})
+	defer ResetHooks()
+
+	logger := WithModuleName("login")
+	logger.Info("login password=hunter2")
+	logger.Print("POST /login password=hunter2")
+
+	for _, msg := range seen {
+		if strings.Contains(msg, "hunter2") {
+			t.Errorf("钩子收到未脱敏的消息: %q", msg)
+		}
+	}
+	if len(seen) != 2 {
+		t.Errorf("钩子调用次数不正确: %q", seen)
+	}
+	if shared["token"] != "abc123" {
+		t.Errorf("脱敏不应修改钩子的字段: %+v", shared)
+	}
+	if strings.Count(buf.String(), "token=******") != 2 {
+		t.Errorf("字段未按名称脱敏: %q", buf.String())
+	}
+}
@@ -0,0 +1,39 @@
+package internal
+
+import "sync"
+
+// rotateLoggers 记录当前打开的所有 RotateLogger，便于统一管理
+var rotateLoggers loggerRegistry
+
+type loggerRegistry struct {
+	lock    sync.RWMutex
+	loggers []*RotateLogger
+}
+
+func (r *loggerRegistry) add(l *RotateLogger) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.loggers = append(r.loggers, l)
+}
+
+func (r *loggerRegistry) remove(l *RotateLogger) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, v := range r.loggers {
+		if v == l {
+			r.loggers = append(r.loggers[:i], r.loggers[i+1:]...)
+			return
+		}
+	}
+}
+
+// snapshot 返回当前所有 RotateLogger 的副本，按创建顺序排列
+func (r *loggerRegistry) snapshot() []*RotateLogger {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	loggers := make([]*RotateLogger, len(r.loggers))
+	copy(loggers, r.loggers)
+	return loggers
+}
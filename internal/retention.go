@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// RetentionReasonCount 表示备份数量超过 MaxBackups
+	RetentionReasonCount = "count"
+	// RetentionReasonSize 表示日志流占用超过 MaxTotalSize
+	RetentionReasonSize = "size"
+	// RetentionReasonAge 表示文件超过保留时长
+	RetentionReasonAge = "age"
+	// RetentionReasonDisk 表示归档文件占用超过磁盘阈值或剩余空间不足
+	RetentionReasonDisk = "disk"
+	// RetentionReasonRule 表示自定义轮转规则返回的过期文件
+	RetentionReasonRule = "rule"
+)
+
+var (
+	retentionDryRun  uint32
+	retentionHandler atomic.Value
+)
+
+type (
+	// RetentionItem 描述一个将被保留策略删除的归档文件
+	RetentionItem struct {
+		File    string    // 文件路径
+		Reason  string    // 删除原因，取值为 RetentionReason* 常量
+		Size    int64     // 文件大小
+		ModTime time.Time // 文件修改时间
+	}
+
+	// retentionPlanner 由可以给出删除原因的轮转规则实现
+	retentionPlanner interface {
+		PlanOutdatedFiles() []RetentionItem
+	}
+)
+
+// PlanRetention 返回所有文件输出当前会被保留策略删除的文件，不做任何删除
+func PlanRetention() []RetentionItem {
+	var items []RetentionItem
+	for _, l := range rotateLoggers.snapshot() {
+		items = append(items, l.planRetention()...)
+	}
+
+	return items
+}
+
+// SetRetentionDryRun 设置保留策略演练模式，开启后只记录删除决策而不真正删除文件
+func SetRetentionDryRun(enabled bool) {
+	if enabled {
+		atomic.StoreUint32(&retentionDryRun, 1)
+	} else {
+		atomic.StoreUint32(&retentionDryRun, 0)
+	}
+}
+
+// IsRetentionDryRun 返回是否处于保留策略演练模式
+func IsRetentionDryRun() bool {
+	return atomic.LoadUint32(&retentionDryRun) == 1
+}
+
+// SetRetentionHandler 设置文件被保留策略实际删除后的回调，传入 nil 取消回调
+func SetRetentionHandler(fn func(item RetentionItem)) {
+	retentionHandler.Store(fn)
+}
+
+func notifyRetention(item RetentionItem) {
+	fn, ok := retentionHandler.Load().(func(item RetentionItem))
+	if !ok || fn == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			writeError(r)
+		}
+	}()
+	fn(item)
+}
+
+// newRetentionItems 按 files 的顺序（从旧到新）生成删除计划
+func newRetentionItems(files []string, outdated map[string]string) []RetentionItem {
+	var items []RetentionItem
+	for _, f := range files {
+		reason, ok := outdated[f]
+		if !ok {
+			continue
+		}
+
+		item := RetentionItem{
+			File:   f,
+			Reason: reason,
+		}
+		if info, err := os.Stat(f); err == nil {
+			item.Size = info.Size()
+			item.ModTime = info.ModTime()
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func retentionFiles(items []RetentionItem) []string {
+	var files []string
+	for _, item := range items {
+		files = append(files, item.File)
+	}
+
+	return files
+}
@@ -16,81 +16,145 @@ func WithModuleName(moduleName string) Logger {
 }
 
 func (l *richLogger) Trace(v ...any) {
-	if !shallLog(TraceLevel) {
+	if !l.shallEmit(TraceLevel) {
 		return
 	}
-	getWriter().Trace(l.formatMessage(fmt.Sprint(v...)))
+	l.output(TraceLevel, fmt.Sprint(v...))
 }
 
 func (l *richLogger) Tracef(format string, v ...any) {
-	if !shallLog(TraceLevel) {
+	if !l.shallEmit(TraceLevel) {
 		return
 	}
-	getWriter().Trace(l.formatMessage(fmt.Sprintf(format, v...)))
+	l.output(TraceLevel, fmt.Sprintf(format, v...))
 }
 
 func (l *richLogger) Debug(v ...any) {
-	if !shallLog(DebugLevel) {
+	if !l.shallEmit(DebugLevel) {
 		return
 	}
-	getWriter().Debug(l.formatMessage(fmt.Sprint(v...)))
+	l.output(DebugLevel, fmt.Sprint(v...))
 }
 
 func (l *richLogger) Debugf(format string, v ...any) {
-	if !shallLog(DebugLevel) {
+	if !l.shallEmit(DebugLevel) {
 		return
 	}
-	getWriter().Debug(l.formatMessage(fmt.Sprintf(format, v...)))
+	l.output(DebugLevel, fmt.Sprintf(format, v...))
 }
 
 func (l *richLogger) Error(v ...any) {
-	if !shallLog(ErrorLevel) {
+	if !l.shallEmit(ErrorLevel) {
 		return
 	}
-	getWriter().Error(l.formatMessage(fmt.Sprint(v...)))
+	args, opts := splitOptions(v)
+	l.emit(ErrorLevel, withErrorDetail(ErrorLevel, fmt.Sprint(args...), args, opts))
 }
 
 func (l *richLogger) Errorf(format string, v ...any) {
-	if !shallLog(ErrorLevel) {
+	if !l.shallEmit(ErrorLevel) {
 		return
 	}
-	getWriter().Error(l.formatMessage(fmt.Sprintf(format, v...)))
+	args, opts := splitOptions(v)
+	l.emit(ErrorLevel, withErrorDetail(ErrorLevel, fmt.Sprintf(format, args...), args, opts))
+}
+
+// Panic 即使日志级别被关闭也会 panic
+func (l *richLogger) Panic(v ...any) {
+	args, opts := splitOptions(v)
+	l.panic(fmt.Sprint(args...), args, opts)
+}
+
+func (l *richLogger) Panicf(format string, v ...any) {
+	args, opts := splitOptions(v)
+	l.panic(fmt.Sprintf(format, args...), args, opts)
+}
+
+// Fatal 即使日志级别被关闭也会退出进程
+func (l *richLogger) Fatal(v ...any) {
+	args, opts := splitOptions(v)
+	l.fatal(fmt.Sprint(args...), args, opts)
+}
+
+func (l *richLogger) Fatalf(format string, v ...any) {
+	args, opts := splitOptions(v)
+	l.fatal(fmt.Sprintf(format, args...), args, opts)
+}
+
+func (l *richLogger) panic(msg string, args []any, opts errorOptions) {
+	if l.shallEmit(PanicLevel) {
+		l.emit(PanicLevel, withErrorDetail(PanicLevel, msg, args, opts))
+	}
+	flushBeforeExit()
+	panic(msg)
+}
+
+func (l *richLogger) fatal(msg string, args []any, opts errorOptions) {
+	if l.shallEmit(FatalLevel) {
+		l.emit(FatalLevel, withErrorDetail(FatalLevel, msg, args, opts))
+	}
+	flushBeforeExit()
+	exitFunc(1)
 }
 
 func (l *richLogger) Warn(v ...any) {
-	if !shallLog(WarnLevel) {
+	if !l.shallEmit(WarnLevel) {
 		return
 	}
-	getWriter().Warn(l.formatMessage(fmt.Sprint(v...)))
+	l.output(WarnLevel, fmt.Sprint(v...))
 }
 
 func (l *richLogger) Warnf(format string, v ...any) {
-	if !shallLog(WarnLevel) {
+	if !l.shallEmit(WarnLevel) {
 		return
 	}
-	getWriter().Warn(l.formatMessage(fmt.Sprintf(format, v...)))
+	l.output(WarnLevel, fmt.Sprintf(format, v...))
 }
 
 func (l *richLogger) Info(v ...any) {
-	if !shallLog(InfoLevel) {
+	if !l.shallEmit(InfoLevel) {
 		return
 	}
-	getWriter().Info(l.formatMessage(fmt.Sprint(v...)))
+	l.output(InfoLevel, fmt.Sprint(v...))
 }
 
 func (l *richLogger) Infof(format string, v ...any) {
-	if !shallLog(InfoLevel) {
+	if !l.shallEmit(InfoLevel) {
+		return
+	}
+	l.output(InfoLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *richLogger) Log(level uint32, v ...any) {
+	if !l.shallEmit(level) {
+		return
+	}
+	l.output(level, fmt.Sprint(v...))
+}
+
+func (l *richLogger) Logf(level uint32, format string, v ...any) {
+	if !l.shallEmit(level) {
 		return
 	}
-	getWriter().Info(l.formatMessage(fmt.Sprintf(format, v...)))
+	l.output(level, fmt.Sprintf(format, v...))
 }
 
 func (l *richLogger) Print(args ...any) {
-	getWriter().AccessRecord(fmt.Sprint(args...))
+	l.access(fmt.Sprint(args...))
 }
 
 func (l *richLogger) Printf(format string, args ...any) {
-	getWriter().AccessRecord(fmt.Sprintf(format, args...))
+	l.access(fmt.Sprintf(format, args...))
+}
+
+// access 转义、脱敏并经过日志钩子处理后写入访问日志
+func (l *richLogger) access(msg string) {
+	msg, keep := runHooks(AccessLevel, l.moduleName, l.traceId, redact(escapeMessage(msg)))
+	if !keep {
+		return
+	}
+
+	getWriter().AccessRecord(msg)
 }
 
 func (l *richLogger) WithTraceId(traceId string) Logger {
@@ -100,8 +164,53 @@ func (l *richLogger) WithTraceId(traceId string) Logger {
 	}
 }
 
+// WriteRawString 写入已格式化的日志，如子进程的 ELogItem.Content，写入前脱敏
 func (l *richLogger) WriteRawString(msg string) {
-	getWriter().WriteRawString(msg)
+	getWriter().WriteRawString(redact(msg))
+}
+
+// shallEmit 返回日志是否需要写入日志文件、最近日志缓冲区或者链路缓存
+func (l *richLogger) shallEmit(level uint32) bool {
+	return shallEmit(level) || (len(l.traceId) > 0 && shallBuffer(level))
+}
+
+// output 转义并截断调用方的消息后写入
+func (l *richLogger) output(level uint32, msg string) {
+	l.emit(level, escapeMessage(msg))
+}
+
+// emit 将已经转义的消息脱敏并经过日志钩子处理后写入最近日志缓冲区，低于日志级别的日志按链路缓存，通过写入器链的级别检查后写入
+// 链路中出现 ERR 时，先写入该链路缓存的日志
+func (l *richLogger) emit(level uint32, msg string) {
+	msg, keep := runHooks(level, l.moduleName, l.traceId, redact(msg))
+	if !keep {
+		return
+	}
+
+	msg = l.formatMessage(msg)
+	captureRecent(level, msg)
+
+	if len(l.traceId) > 0 {
+		if shallBuffer(level) {
+			traces.buffer(l.traceId, level, msg)
+		} else if levelAtLeast(level, ErrorLevel) {
+			traces.flush(l.traceId)
+		}
+	}
+
+	if !shallLog(level) {
+		return
+	}
+
+	// 匹配分流规则的日志写入对应的日志文件代替服务日志，控制台和错误日志照常写入
+	if out := matchRoute(l.moduleName, level); out != nil {
+		if levelAtLeast(level, effectiveLevel()) {
+			output(out, levelTag(level), msg)
+		}
+		writeRouted(getWriter(), level, msg)
+		return
+	}
+	writeLevel(getWriter(), level, msg)
 }
 
 func (l *richLogger) formatMessage(msg string) string {
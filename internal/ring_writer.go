@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRingRecords = 1000
+
+var (
+	recentRing atomic.Value
+	// captureLevel 是写入 recentRing 的最低日志级别，不受日志级别的限制
+	captureLevel uint32 = DisableLevel
+)
+
+type (
+	// RingRecord 是内存环形缓冲区中的一条日志
+	RingRecord struct {
+		Time    time.Time // 记录时间
+		Level   string    // 日志级别，原始字符串为空
+		Content string    // 格式化后的完整日志行
+	}
+
+	// RingWriter 在内存中保留最近的日志，超过条数或字节数上限时丢弃最旧的日志
+	RingWriter struct {
+		lock       sync.Mutex
+		maxRecords int
+		maxBytes   int
+		records    []RingRecord
+		bytes      int
+		nextId     int
+		streams    map[int]func(record RingRecord)
+	}
+)
+
+// NewRingWriter 返回一个保留最近 maxRecords 条或 maxBytes 字节日志的 RingWriter
+// 两者都小于等于0时保留最近1000条
+func NewRingWriter(maxRecords, maxBytes int) *RingWriter {
+	if maxRecords <= 0 && maxBytes <= 0 {
+		maxRecords = defaultRingRecords
+	}
+
+	return &RingWriter{
+		maxRecords: maxRecords,
+		maxBytes:   maxBytes,
+	}
+}
+
+func (r *RingWriter) Close() error            { return nil }
+func (r *RingWriter) Trace(v any)             { r.push(LevelTrace, formatOutput(LevelTrace, v)) }
+func (r *RingWriter) Debug(v any)             { r.push(LevelDebug, formatOutput(LevelDebug, v)) }
+func (r *RingWriter) Warn(v any)              { r.push(LevelWarn, formatOutput(LevelWarn, v)) }
+func (r *RingWriter) Error(v any)             { r.push(LevelError, formatOutput(LevelError, v)) }
+func (r *RingWriter) Info(v any)              { r.push(LevelInfo, formatOutput(LevelInfo, v)) }
+func (r *RingWriter) Panic(v any)             { r.push(LevelPanic, formatOutput(LevelPanic, v)) }
+func (r *RingWriter) Fatal(v any)             { r.push(LevelFatal, formatOutput(LevelFatal, v)) }
+func (r *RingWriter) WriteRawString(v string) { r.push("", v) }
+
+func (r *RingWriter) Log(level uint32, v any) {
+	tag := levelTag(level)
+	r.push(tag, formatOutput(tag, v))
+}
+
+func (r *RingWriter) AccessRecord(v any) {
+	r.push(levelAccessRecord, formatOutput(levelAccessRecord, v))
+}
+
+// Snapshot 按写入顺序返回当前保留的日志
+func (r *RingWriter) Snapshot() []RingRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	records := make([]RingRecord, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+// WriteTo 按写入顺序将当前保留的日志写入 w
+func (r *RingWriter) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, record := range r.Snapshot() {
+		n, err := io.WriteString(w, record.Content)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// Stream 订阅之后写入的日志，返回取消订阅的函数
+// 回调在写日志的协程中同步执行，不要在回调中阻塞
+func (r *RingWriter) Stream(fn func(record RingRecord)) (cancel func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.streams == nil {
+		r.streams = make(map[int]func(record RingRecord))
+	}
+	id := r.nextId
+	r.nextId++
+	r.streams[id] = fn
+
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		delete(r.streams, id)
+	}
+}
+
+func (r *RingWriter) push(level, content string) {
+	record := RingRecord{
+		Time:    time.Now(),
+		Level:   level,
+		Content: content,
+	}
+
+	r.lock.Lock()
+	r.records = append(r.records, record)
+	r.bytes += len(content)
+	for len(r.records) > 1 && r.overflow() {
+		r.bytes -= len(r.records[0].Content)
+		r.records[0] = RingRecord{}
+		r.records = r.records[1:]
+	}
+
+	fns := make([]func(record RingRecord), 0, len(r.streams))
+	for _, fn := range r.streams {
+		fns = append(fns, fn)
+	}
+	r.lock.Unlock()
+
+	for _, fn := range fns {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					writeError(p)
+				}
+			}()
+			fn(record)
+		}()
+	}
+}
+
+func (r *RingWriter) overflow() bool {
+	return (r.maxRecords > 0 && len(r.records) > r.maxRecords) ||
+		(r.maxBytes > 0 && r.bytes > r.maxBytes)
+}
+
+// SetRecentRing 设置保留最近日志的环形缓冲区，传入 nil 取消
+// 不低于 level 的日志都会写入 ring，不受日志级别的限制，用于保留出错前的调试上下文
+func SetRecentRing(ring *RingWriter, level uint32) {
+	if ring == nil {
+		level = DisableLevel
+	}
+
+	recentRing.Store(ring)
+	atomic.StoreUint32(&captureLevel, level)
+}
+
+// RecentRing 返回通过 SetRecentRing 设置的环形缓冲区，未设置时返回 nil
+func RecentRing() *RingWriter {
+	ring, _ := recentRing.Load().(*RingWriter)
+	return ring
+}
+
+func shallCapture(level uint32) bool {
+	return levelAtLeast(level, atomic.LoadUint32(&captureLevel))
+}
+
+// shallEmit 返回给定级别的日志是否需要写入日志文件或者最近日志缓冲区
+func shallEmit(level uint32) bool {
+	return shallLog(level) || shallCapture(level)
+}
+
+func captureRecent(level uint32, msg string) {
+	if !shallCapture(level) {
+		return
+	}
+
+	if ring := RecentRing(); ring != nil {
+		writeLevel(ring, level, msg)
+	}
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRingWriter_Limits(t *testing.T) {
+	ring := NewRingWriter(2, 0)
+	ring.Info("first")
+	ring.Info("second")
+	ring.Error("third")
+
+	records := ring.Snapshot()
+	if len(records) != 2 {
+		t.Fatalf("期望保留2条日志, 得到 %d", len(records))
+	}
+	if !strings.Contains(records[0].Content, "second") || records[1].Level != LevelError {
+		t.Errorf("期望保留最新的日志, 得到 %+v", records)
+	}
+
+	ring = NewRingWriter(0, 10)
+	ring.WriteRawString("12345")
+	ring.WriteRawString("67890")
+	ring.WriteRawString("abc")
+	var buf bytes.Buffer
+	if _, err := ring.WriteTo(&buf); err != nil {
+		t.Fatalf("导出日志失败: %v", err)
+	}
+	if buf.String() != "67890abc" {
+		t.Errorf("期望按字节数保留最近日志, 得到 %q", buf.String())
+	}
+
+	// 单条超过上限时仍然保留最新的一条
+	ring.WriteRawString("0123456789abc")
+	if records := ring.Snapshot(); len(records) != 1 {
+		t.Errorf("期望只保留最新的一条, 得到 %+v", records)
+	}
+}
+
+func TestRingWriter_CaptureBelowLevel(t *testing.T) {
+	oldLevel := GetLevel()
+	SetLevel(ErrorLevel)
+	defer SetLevel(oldLevel)
+
+	ring := NewRingWriter(10, 0)
+	SetRecentRing(ring, DebugLevel)
+	defer SetRecentRing(nil, 0)
+
+	var streamed []RingRecord
+	cancel := ring.Stream(func(record RingRecord) {
+		streamed = append(streamed, record)
+	})
+
+	logger := WithModuleName("ring")
+	logger.Trace("trace message")
+	logger.Debugf("debug %d", 1)
+	logger.Error("error message")
+	cancel()
+	logger.Info("after cancel")
+
+	records := ring.Snapshot()
+	if len(records) != 3 {
+		t.Fatalf("期望记录 DEB 及以上的3条日志, 得到 %+v", records)
+	}
+	if records[0].Level != LevelDebug || !strings.Contains(records[0].Content, "[ring] debug 1") {
+		t.Errorf("低于日志级别的调试日志应该被记录, 得到 %+v", records[0])
+	}
+	if len(streamed) != 2 {
+		t.Errorf("取消订阅后不应该再收到日志, 得到 %+v", streamed)
+	}
+}
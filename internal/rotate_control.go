@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// exitFlushTimeout 退出前同步刷新日志文件的最长等待时间
+const exitFlushTimeout = 3 * time.Second
+
+// exitFunc 用于 Fatal 退出进程，测试时可以替换
+var exitFunc = os.Exit
+
+const (
+	// controlRotate 立即轮转当前日志文件
+	controlRotate = iota
+	// controlReopen 不重命名，直接重新打开日志文件路径，配合外部 logrotate 使用
+	controlReopen
+	// controlFlush 将通道中已有的日志写入文件并同步到磁盘
+	controlFlush
+)
+
+// controlRequest 是发送给写入协程的控制请求，保证与写入操作串行执行
+type controlRequest struct {
+	action int
+	result chan error
+}
+
+// recoveryRequest 是健康检查器发给写入协程的恢复尝试，写入 msg 成功表示已经恢复
+type recoveryRequest struct {
+	msg    string
+	result chan error
+}
+
+// Rotate 立即轮转日志文件，已写入通道的日志会先写入旧文件
+func (l *RotateLogger) Rotate() error {
+	return l.sendControl(controlRotate)
+}
+
+// Reopen 重新打开日志文件路径而不重命名
+// 适用于外部 logrotate 移动或截断(copytruncate)文件之后通知日志器
+func (l *RotateLogger) Reopen() error {
+	return l.sendControl(controlReopen)
+}
+
+// Flush 将已写入通道的日志同步写入文件，返回时日志已经落盘
+func (l *RotateLogger) Flush() error {
+	return l.sendControl(controlFlush)
+}
+
+func (l *RotateLogger) sendControl(action int) error {
+	req := controlRequest{
+		action: action,
+		result: make(chan error, 1),
+	}
+
+	select {
+	case l.control <- req:
+	case <-l.done:
+		return ErrLogFileClosed
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-l.done:
+		return ErrLogFileClosed
+	}
+}
+
+// handleControl 在写入协程中执行控制请求
+func (l *RotateLogger) handleControl(req controlRequest) {
+	l.drain()
+
+	var err error
+	switch req.action {
+	case controlRotate:
+		if err = l.rotate(); err == nil {
+			l.rule.MarkRotated()
+			atomic.StoreInt64(&l.currentSize, 0)
+		}
+	case controlReopen:
+		err = l.reopen()
+	case controlFlush:
+		// 使用备用输出期间日志已经写入备用输出，原来的文件可能已经关闭
+		if l.fp != nil && !l.degraded {
+			err = l.fp.Sync()
+		}
+	default:
+		err = fmt.Errorf("unknown control action: %d", req.action)
+	}
+
+	req.result <- err
+}
+
+// recoverFile 请求写入协程重新打开并测试写入日志文件，由健康检查协程调用
+func (l *RotateLogger) recoverFile(msg string) error {
+	req := recoveryRequest{
+		msg:    msg,
+		result: make(chan error, 1),
+	}
+
+	select {
+	case l.recovery <- req:
+	case <-l.done:
+		return ErrLogFileClosed
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-l.done:
+		return ErrLogFileClosed
+	}
+}
+
+// waitRecover 在写入协程中阻塞等待恢复，期间执行健康检查器的恢复尝试
+func (l *RotateLogger) waitRecover() bool {
+	for {
+		select {
+		case req := <-l.recovery:
+			req.result <- l.health.testWrite(req.msg)
+		case <-l.health.recoverChan:
+			return true
+		case <-l.done:
+			return false
+		}
+	}
+}
+
+// drain 将通道中已有的日志写完
+func (l *RotateLogger) drain() {
+	for {
+		select {
+		case event := <-l.channel:
+			l.write(event)
+		default:
+			return
+		}
+	}
+}
+
+// reopen 先打开日志文件路径，成功后才关闭原来的文件，打开失败时继续使用原来的文件
+func (l *RotateLogger) reopen() error {
+	old, size := l.fp, atomic.LoadInt64(&l.currentSize)
+	l.fp = nil
+	atomic.StoreInt64(&l.currentSize, 0)
+	if err := l.initialize(); err != nil {
+		if l.fp != nil {
+			_ = l.fp.Close()
+		}
+		l.fp = old
+		atomic.StoreInt64(&l.currentSize, size)
+		return err
+	}
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			Errorf("failed to close log file: %s, error: %v", l.filename, err)
+		}
+	}
+	return nil
+}
+
+// uniqueBackupFilename 备份文件已存在时追加序号，避免同一周期内多次轮转覆盖旧的备份
+func uniqueBackupFilename(name string) string {
+	if !fileExists(name) && !fileExists(name+gzipExt) {
+		return name
+	}
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s.%d", name, i)
+		if !fileExists(candidate) && !fileExists(candidate+gzipExt) {
+			return candidate
+		}
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// RotateAll 立即轮转所有文件输出
+func RotateAll() error {
+	var be BatchError
+	for _, l := range rotateLoggers.snapshot() {
+		be.Add(l.Rotate())
+	}
+	return be.Err()
+}
+
+// FlushAll 同步刷新所有文件输出，超过 timeout 时返回 ErrFlushTimeout
+// 写入协程可能因为磁盘故障阻塞，进程退出前调用时需要设置超时
+func FlushAll(timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		var be BatchError
+		for _, l := range rotateLoggers.snapshot() {
+			be.Add(l.Flush())
+		}
+		result <- be.Err()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		return ErrFlushTimeout
+	}
+}
+
+// flushBeforeExit 进程退出或者 panic 前尽量将已写入通道的日志落盘
+func flushBeforeExit() {
+	if err := FlushAll(exitFlushTimeout); err != nil {
+		log.Printf("failed to flush log files before exit: %v", err)
+	}
+}
+
+// ReopenAll 重新打开所有文件输出的路径
+func ReopenAll() error {
+	var be BatchError
+	for _, l := range rotateLoggers.snapshot() {
+		be.Add(l.Reopen())
+	}
+	return be.Err()
+}
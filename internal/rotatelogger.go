@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,11 +25,14 @@ const (
 	megaBytes            = 1 << 20
 	gzipFileMode         = 0o400
 	preGzipFileMode      = 0o600
+	defaultDiskThreshold = 80 // 归档文件占用磁盘容量的默认百分比阈值
 )
 
 var (
-	ErrLogFileClosed = errors.New("error: log file closed")
-	fileTimeFormat   = "2006-01-02T15:04:05.000000000Z"
+	ErrLogFileClosed  = errors.New("error: log file closed")
+	ErrLogFileNotOpen = errors.New("error: log file not open")
+	ErrFlushTimeout   = errors.New("error: flush log files timeout")
+	fileTimeFormat    = "2006-01-02T15:04:05.000000000Z"
 )
 
 type (
@@ -43,29 +47,42 @@ type (
 
 	// RotateLogger 是一个可以按照给定规则轮转日志文件的日志器
 	RotateLogger struct {
-		filename      string
-		backup        string
-		fp            *os.File
-		channel       chan []byte
-		done          chan PlaceholderType
-		rule          RotateRule
-		compress      bool
-		retryCompress chan string
+		filename string
+		backup   string
+		fp       *os.File
+		channel  chan []byte
+		control  chan controlRequest
+		recovery chan recoveryRequest // 健康检查器的恢复尝试，文件只在写入协程中打开、关闭和替换
+		done     chan PlaceholderType
+		rule     RotateRule
+		compress bool
 		// 不能使用 threading.RoutineGroup，因为会导致循环导入
 		waitGroup   sync.WaitGroup
 		closeOnce   sync.Once
 		currentSize int64
+		holdWarned  uint32       // 保全期间是否已经打印过告警
+		opened      atomic.Value // 当前打开文件的 os.FileInfo，用于识别文件被替换
+		reopening   uint32       // 是否有等待执行的重新打开请求
+
+		fallback *fallbackOutput // 主日志故障时的备用输出，未配置时故障期间阻塞等待恢复
+		degraded bool            // 是否正在使用备用输出，仅在写入协程中访问
 
 		health *HealthChecker // 新增健康检查器
 	}
 
+	// RuleOption 定义了自定义轮转规则保留策略的方法
+	RuleOption func(rule *DailyRotateRule)
+
 	// DailyRotateRule 是一个按天轮转日志文件的规则
 	DailyRotateRule struct {
-		rotatedTime string
-		filename    string
-		delimiter   string
-		days        int
-		gzip        bool
+		rotatedTime   string
+		filename      string
+		delimiter     string
+		days          int
+		gzip          bool
+		maxTotalSize  int64         // 备份文件与当前文件合计占用的最大字节数，0表示不限制
+		diskThreshold int           // 归档文件占用磁盘容量的百分比阈值
+		maxAge        time.Duration // 备份文件的最长保留时长，优先于 days
 	}
 
 	// SizeLimitRotateRule 是一个基于文件大小的日志轮转规则
@@ -77,13 +94,42 @@ type (
 )
 
 // DefaultRotateRule 返回默认的日志轮转规则，目前是 DailyRotateRule
-func DefaultRotateRule(filename, delimiter string, days int, gzip bool) RotateRule {
-	return &DailyRotateRule{
-		rotatedTime: getNowDate(),
-		filename:    filename,
-		delimiter:   delimiter,
-		days:        days,
-		gzip:        gzip,
+func DefaultRotateRule(filename, delimiter string, days int, gzip bool, opts ...RuleOption) RotateRule {
+	rule := &DailyRotateRule{
+		rotatedTime:   getNowDate(),
+		filename:      filename,
+		delimiter:     delimiter,
+		days:          days,
+		gzip:          gzip,
+		diskThreshold: defaultDiskThreshold,
+	}
+	for _, opt := range opts {
+		opt(rule)
+	}
+
+	return rule
+}
+
+// WithRuleMaxTotalSize 限制备份文件与当前文件合计占用的字节数，超出时从最旧的备份开始删除
+func WithRuleMaxTotalSize(size int64) RuleOption {
+	return func(rule *DailyRotateRule) {
+		rule.maxTotalSize = size
+	}
+}
+
+// WithRuleMaxAge 自定义备份文件的最长保留时长，按文件修改时间判断，设置后优先于保留天数
+func WithRuleMaxAge(age time.Duration) RuleOption {
+	return func(rule *DailyRotateRule) {
+		rule.maxAge = age
+	}
+}
+
+// WithRuleDiskThreshold 自定义归档文件占用磁盘容量的百分比阈值，取值范围 1-100
+func WithRuleDiskThreshold(percent int) RuleOption {
+	return func(rule *DailyRotateRule) {
+		if percent > 0 && percent <= 100 {
+			rule.diskThreshold = percent
+		}
 	}
 }
 
@@ -101,9 +147,14 @@ func (r *DailyRotateRule) FilePathPattern() string {
 	return fmt.Sprintf("%s%s*", r.filename, r.delimiter)
 }
 
-// OutdatedFiles 返回超过保留天数的文件列表
+// OutdatedFiles 返回超过保留时长或总大小限制的文件列表
 func (r *DailyRotateRule) OutdatedFiles() []string {
-	if r.days <= 0 {
+	return retentionFiles(r.PlanOutdatedFiles())
+}
+
+// PlanOutdatedFiles 返回超过保留时长、总大小限制或因磁盘压力需要删除的文件及删除原因
+func (r *DailyRotateRule) PlanOutdatedFiles() []RetentionItem {
+	if r.retentionAge() <= 0 && r.maxTotalSize <= 0 && currentPressureStage() < pressureDeleteBackups {
 		return nil
 	}
 
@@ -120,24 +171,94 @@ func (r *DailyRotateRule) OutdatedFiles() []string {
 		return nil
 	}
 
-	var buf strings.Builder
-	boundary := time.Now().UTC().Add(-time.Hour * time.Duration(hoursPerDay*r.days)).Format(dateFormat)
-	buf.WriteString(r.filename)
-	buf.WriteString(r.delimiter)
-	buf.WriteString(boundary)
-	if r.gzip {
-		buf.WriteString(gzipExt)
+	sort.Strings(files)
+
+	outdated := make(map[string]string)
+	r.markExpired(files, outdated)
+	r.limitTotalSize(files, outdated)
+	r.markPressure(files, outdated)
+
+	return newRetentionItems(files, outdated)
+}
+
+// retentionAge 返回备份文件的保留时长，未设置 maxAge 时按保留天数计算，0表示永久保留
+func (r *DailyRotateRule) retentionAge() time.Duration {
+	if r.maxAge > 0 {
+		return r.maxAge
+	}
+	if r.days > 0 {
+		return time.Hour * time.Duration(hoursPerDay*r.days)
 	}
-	boundaryFile := buf.String()
 
-	var outdates []string
-	for _, file := range files {
-		if file < boundaryFile {
-			outdates = append(outdates, file)
+	return 0
+}
+
+// markExpired 标记修改时间早于保留时长的文件，不依赖文件名中的时间
+func (r *DailyRotateRule) markExpired(files []string, outdated map[string]string) {
+	maxAge := r.retentionAge()
+	if maxAge <= 0 {
+		return
+	}
+
+	boundary := time.Now().Add(-maxAge)
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			Warnf("failed to get file info: %s, error: %s", f, err)
+			continue
 		}
+		if _, exists := outdated[f]; !exists && info.ModTime().Before(boundary) {
+			outdated[f] = RetentionReasonAge
+		}
+	}
+}
+
+// limitTotalSize 从最旧的备份开始标记删除，直到剩余备份与当前文件的大小之和低于 maxTotalSize
+// files 需要按照从旧到新排序，已在 outdated 中的文件不计入总大小
+func (r *DailyRotateRule) limitTotalSize(files []string, outdated map[string]string) {
+	if r.maxTotalSize <= 0 {
+		return
+	}
+
+	var totalSize int64
+	if info, err := os.Stat(r.filename); err == nil {
+		totalSize = info.Size()
+	}
+
+	fileSizes := make(map[string]int64, len(files))
+	for _, f := range files {
+		if _, exists := outdated[f]; exists {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			Warnf("failed to get file size: %s, error: %s", f, err)
+			continue
+		}
+		fileSizes[f] = info.Size()
+		totalSize += info.Size()
+	}
+
+	for _, f := range files {
+		if totalSize < r.maxTotalSize {
+			break
+		}
+		size, ok := fileSizes[f]
+		if !ok {
+			continue
+		}
+		outdated[f] = RetentionReasonSize
+		totalSize -= size
+	}
+}
+
+// diskUsageRatio 返回归档文件允许占用磁盘容量的比例
+func (r *DailyRotateRule) diskUsageRatio() float64 {
+	if r.diskThreshold <= 0 || r.diskThreshold > 100 {
+		return defaultDiskThreshold / 100.0
 	}
 
-	return outdates
+	return float64(r.diskThreshold) / 100
 }
 
 // ShallRotate 检查文件是否应该进行轮转
@@ -146,18 +267,25 @@ func (r *DailyRotateRule) ShallRotate(_ int64) bool {
 }
 
 // NewSizeLimitRotateRule 返回一个基于大小限制的轮转规则
-func NewSizeLimitRotateRule(filename, delimiter string, days, maxSize, maxBackups int, gzip bool) RotateRule {
-	return &SizeLimitRotateRule{
+func NewSizeLimitRotateRule(filename, delimiter string, days, maxSize, maxBackups int, gzip bool,
+	opts ...RuleOption) RotateRule {
+	rule := &SizeLimitRotateRule{
 		DailyRotateRule: DailyRotateRule{
-			rotatedTime: getNowDateInRFC3339Format(),
-			filename:    filename,
-			delimiter:   delimiter,
-			days:        days,
-			gzip:        gzip,
+			rotatedTime:   getNowDateInRFC3339Format(),
+			filename:      filename,
+			delimiter:     delimiter,
+			days:          days,
+			gzip:          gzip,
+			diskThreshold: defaultDiskThreshold,
 		},
 		maxSize:    int64(maxSize) * megaBytes,
 		maxBackups: maxBackups,
 	}
+	for _, opt := range opts {
+		opt(&rule.DailyRotateRule)
+	}
+
+	return rule
 }
 
 func (r *SizeLimitRotateRule) BackupFileName() string {
@@ -179,6 +307,11 @@ func (r *SizeLimitRotateRule) FilePathPattern() string {
 }
 
 func (r *SizeLimitRotateRule) OutdatedFiles() []string {
+	return retentionFiles(r.PlanOutdatedFiles())
+}
+
+// PlanOutdatedFiles 返回超过备份数量、磁盘阈值、保留时长或总大小限制的文件及删除原因
+func (r *SizeLimitRotateRule) PlanOutdatedFiles() []RetentionItem {
 	dir := filepath.Dir(r.filename)
 	prefix, ext := r.parseFilename()
 
@@ -198,13 +331,14 @@ func (r *SizeLimitRotateRule) OutdatedFiles() []string {
 	}
 
 	sort.Strings(files)
+	allFiles := files
 
-	outdated := make(map[string]PlaceholderType)
+	outdated := make(map[string]string)
 
 	// 1. 检查备份数量是否超过限制
 	if r.maxBackups > 0 && len(files) > r.maxBackups {
 		for _, f := range files[:len(files)-r.maxBackups] {
-			outdated[f] = Placeholder
+			outdated[f] = RetentionReasonCount
 		}
 		files = files[len(files)-r.maxBackups:] // 更新files列表为保留的文件
 	}
@@ -243,17 +377,18 @@ func (r *SizeLimitRotateRule) OutdatedFiles() []string {
 	maxTotalSize := int64(math.MaxInt64)
 	if diskTotalSize, err := GetDirOnDiskTotalSize(dir); err != nil || diskTotalSize == 0 {
 		// 获取不到磁盘实际大小，也用配置的个数和大小兜底磁盘容量限制
-		maxTotalSize = int64(float64(r.maxSize*int64(r.maxBackups)) * 0.8)
+		maxTotalSize = int64(float64(r.maxSize*int64(r.maxBackups)) * r.diskUsageRatio())
 	} else {
-		// 滚卷时保证归档文件占用磁盘容量不超过磁盘大小80%阈值
-		maxTotalSize = int64(float64(diskTotalSize) * 0.8)
+		// 滚卷时保证归档文件占用磁盘容量不超过磁盘大小的阈值（默认80%）
+		maxTotalSize = int64(float64(diskTotalSize) * r.diskUsageRatio())
 	}
 
-	if r.maxSize > 0 && r.maxBackups > 0 {
+	// 开启磁盘压力保护时，只有保护升级到删除备份阶段才按磁盘空间删除
+	if r.maxSize > 0 && r.maxBackups > 0 && pressureDeletionAllowed() {
 		if totalSize > maxTotalSize || freeSize < guessGzSize {
 			for _, f := range files {
 				if _, exists := outdated[f]; !exists {
-					outdated[f] = Placeholder
+					outdated[f] = RetentionReasonDisk
 					totalSize -= fileSizes[f]
 				}
 				if totalSize <= maxTotalSize {
@@ -263,27 +398,16 @@ func (r *SizeLimitRotateRule) OutdatedFiles() []string {
 		}
 	}
 
-	// 3. 检查是否有过期的文件（按天数）
-	if r.days > 0 {
-		boundary := time.Now().UTC().Add(-time.Hour * time.Duration(hoursPerDay*r.days)).Format(fileTimeFormat)
-		boundary = strings.Replace(boundary, ":", ".", -1)
-		boundaryFile := filepath.Join(dir, fmt.Sprintf("%s%s%s%s", prefix, r.delimiter, boundary, ext))
-		if r.gzip {
-			boundaryFile += gzipExt
-		}
-		for _, f := range files {
-			if f >= boundaryFile {
-				break
-			}
-			outdated[f] = Placeholder
-		}
-	}
+	// 3. 检查是否有过期的文件（按文件修改时间）
+	r.markExpired(files, outdated)
 
-	var result []string
-	for k := range outdated {
-		result = append(result, k)
-	}
-	return result
+	// 4. 检查备份文件与当前文件的合计大小是否超过 maxTotalSize
+	r.limitTotalSize(files, outdated)
+
+	// 5. 磁盘压力保护最后一步，删除最旧的备份释放空间
+	r.markPressure(files, outdated)
+
+	return newRetentionItems(allFiles, outdated)
 }
 
 func (r *SizeLimitRotateRule) ShallRotate(size int64) bool {
@@ -298,14 +422,18 @@ func (r *SizeLimitRotateRule) parseFilename() (prefix, ext string) {
 }
 
 // NewLogger 返回一个 RotateLogger 实例，给定文件名和规则等
-func NewLogger(filename string, rule RotateRule, compress bool) (*RotateLogger, error) {
+func NewLogger(filename string, rule RotateRule, compress bool, opts ...LoggerOption) (*RotateLogger, error) {
 	l := &RotateLogger{
-		filename:      filename,
-		channel:       make(chan []byte, maxLogItemBufferSize),
-		retryCompress: make(chan string, 100),
-		done:          make(chan PlaceholderType),
-		rule:          rule,
-		compress:      compress,
+		filename: filename,
+		channel:  make(chan []byte, maxLogItemBufferSize),
+		control:  make(chan controlRequest),
+		recovery: make(chan recoveryRequest),
+		done:     make(chan PlaceholderType),
+		rule:     rule,
+		compress: compress,
+	}
+	for _, opt := range opts {
+		opt(l)
 	}
 	if err := l.initialize(); err != nil {
 		return nil, err
@@ -314,6 +442,7 @@ func NewLogger(filename string, rule RotateRule, compress bool) (*RotateLogger,
 	l.health = NewHealthChecker(l)
 	l.health.Start()
 	l.startWorker()
+	rotateLoggers.add(l)
 	return l, nil
 }
 
@@ -322,9 +451,17 @@ func (l *RotateLogger) Close() error {
 	var err error
 
 	l.closeOnce.Do(func() {
+		rotateLoggers.remove(l)
 		close(l.done)
 		l.waitGroup.Wait()
 
+		if l.fallback != nil {
+			l.fallback.close()
+		}
+
+		if l.fp == nil {
+			return
+		}
 		if err = l.fp.Sync(); err != nil {
 			return
 		}
@@ -367,7 +504,7 @@ func (l *RotateLogger) initialize() error {
 			}
 		}
 
-		if l.fp, err = os.Create(l.filename); err != nil {
+		if l.fp, err = createLogFile(l.filename); err != nil {
 			return err
 		}
 		if err = l.fp.Chmod(defaultFileMode); err != nil {
@@ -378,14 +515,57 @@ func (l *RotateLogger) initialize() error {
 			return err
 		}
 
-		l.currentSize = fileInfo.Size()
+		atomic.StoreInt64(&l.currentSize, fileInfo.Size())
 	}
 
 	CloseOnExec(l.fp)
+	l.markOpened()
 
 	return nil
 }
 
+// createLogFile 以追加模式创建日志文件，外部截断(copytruncate)后写入位置仍然在文件末尾
+func createLogFile(name string) (*os.File, error) {
+	return os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_APPEND|os.O_WRONLY, defaultFileMode)
+}
+
+// markOpened 记录当前打开文件的信息
+func (l *RotateLogger) markOpened() {
+	if l.fp == nil {
+		return
+	}
+
+	if info, err := l.fp.Stat(); err == nil {
+		l.opened.Store(info)
+	}
+}
+
+// openedInfo 返回当前打开文件的信息，尚未打开时返回 nil
+func (l *RotateLogger) openedInfo() os.FileInfo {
+	info, _ := l.opened.Load().(os.FileInfo)
+	return info
+}
+
+// requestReopen 异步请求写入协程重新打开日志文件，同一时间只保留一个请求
+// 重新打开失败时继续写入原来的文件，不进入恢复流程
+func (l *RotateLogger) requestReopen(reason string) {
+	if !atomic.CompareAndSwapUint32(&l.reopening, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreUint32(&l.reopening, 0)
+
+		if err := l.Reopen(); err != nil {
+			if !errors.Is(err, ErrLogFileClosed) {
+				log.Printf("failed to reopen log file %s (%s), keep writing to the opened file: %v", l.filename, reason, err)
+			}
+			return
+		}
+		log.Printf("log file %s reopened: %s", l.filename, reason)
+	}()
+}
+
 func (l *RotateLogger) maybeCompressFile(file string) (retry bool) {
 	if !l.compress {
 		return
@@ -408,25 +588,77 @@ func (l *RotateLogger) maybeCompressFile(file string) (retry bool) {
 	return
 }
 
+// planRetention 返回当前规则下将被删除的文件及原因
+func (l *RotateLogger) planRetention() []RetentionItem {
+	if planner, ok := l.rule.(retentionPlanner); ok {
+		return planner.PlanOutdatedFiles()
+	}
+
+	var items []RetentionItem
+	for _, file := range l.rule.OutdatedFiles() {
+		items = append(items, RetentionItem{
+			File:   file,
+			Reason: RetentionReasonRule,
+		})
+	}
+	return items
+}
+
 func (l *RotateLogger) maybeDeleteOutdatedFiles() {
 	defer func() { recover() }()
-	files := l.rule.OutdatedFiles()
-	for _, file := range files {
-		if err := os.Remove(file); err != nil {
-			Errorf("failed to remove outdated or limited file: %s", file)
+	items := l.planRetention()
+
+	if dir := filepath.Dir(l.filename); IsHeld(dir) {
+		l.reportHeldFiles(dir, items)
+		return
+	}
+	atomic.StoreUint32(&l.holdWarned, 0)
+	l.health.ClearDegraded(degradedHeld)
+
+	dryRun := IsRetentionDryRun()
+	for _, item := range items {
+		if dryRun {
+			Infof("[dry-run] would delete outdated or limited file: %s, reason: %s", item.File, item.Reason)
+			continue
+		}
+
+		if err := os.Remove(item.File); err != nil {
+			Errorf("failed to remove outdated or limited file: %s", item.File)
 		} else {
-			Infof("delete outdated or limited file: %s", file)
+			Infof("delete outdated or limited file: %s, reason: %s", item.File, item.Reason)
+			notifyRetention(item)
 		}
 	}
 }
 
+// reportHeldFiles 保全期间不删除任何归档文件，仅通过回调报告本应删除的文件
+func (l *RotateLogger) reportHeldFiles(dir string, items []RetentionItem) {
+	if len(items) == 0 {
+		l.health.ClearDegraded(degradedHeld)
+		return
+	}
+
+	l.health.SetDegraded(degradedHeld)
+	if atomic.CompareAndSwapUint32(&l.holdWarned, 0, 1) {
+		Warnf("retention held, skip deleting %d outdated or limited files of %s", len(items), l.filename)
+	}
+
+	reportDiskPressure(DiskPressure{
+		File:    l.filename,
+		Reason:  holdReason(dir),
+		Blocked: items,
+		Time:    time.Now(),
+	})
+}
+
 func (l *RotateLogger) postRotate(file string) {
-	go func() {
-		if retry := l.maybeCompressFile(file); retry {
-			l.retryCompress <- file
-		}
-		l.maybeDeleteOutdatedFiles()
-	}()
+	if l.compress {
+		// 压缩完成后由工作池负责清理过期文件
+		compressor.submit(compressTask{logger: l, file: file})
+		return
+	}
+
+	go l.maybeDeleteOutdatedFiles()
 }
 
 func (l *RotateLogger) rotate() error {
@@ -440,7 +672,7 @@ func (l *RotateLogger) rotate() error {
 
 	_, err := os.Stat(l.filename)
 	if err == nil && len(l.backup) > 0 {
-		backupFilename := l.getBackupFilename()
+		backupFilename := uniqueBackupFilename(l.getBackupFilename())
 		err = os.Rename(l.filename, backupFilename)
 		if err != nil {
 			return err
@@ -450,9 +682,10 @@ func (l *RotateLogger) rotate() error {
 	}
 
 	l.backup = l.rule.BackupFileName()
-	if l.fp, err = os.Create(l.filename); err == nil {
+	if l.fp, err = createLogFile(l.filename); err == nil {
 		CloseOnExec(l.fp)
 		err = l.fp.Chmod(defaultFileMode)
+		l.markOpened()
 	}
 
 	return err
@@ -468,6 +701,12 @@ func (l *RotateLogger) startWorker() {
 			select {
 			case event := <-l.channel:
 				l.write(event)
+			case req := <-l.control:
+				l.handleControl(req)
+			case req := <-l.recovery:
+				req.result <- l.health.testWrite(req.msg)
+			case <-l.recovered():
+				l.replayFallback()
 			case <-l.done:
 				// avoid losing logs before closing.
 				for {
@@ -491,10 +730,6 @@ func (l *RotateLogger) startWorker() {
 
 		for {
 			select {
-			case filePath := <-l.retryCompress:
-				Warnf("clean some old files and retry compress file: %s", filePath)
-				l.maybeDeleteOutdatedFiles()
-				l.compressLogFile(filePath)
 			case <-timer.C:
 				// 兜底一下每分钟也清理过期文件
 				l.maybeDeleteOutdatedFiles()
@@ -505,27 +740,56 @@ func (l *RotateLogger) startWorker() {
 	}()
 }
 
+// recovered 返回使用备用输出期间的恢复信号通道，未降级时返回 nil 通道
+func (l *RotateLogger) recovered() <-chan struct{} {
+	if l.fallback == nil || !l.degraded {
+		return nil
+	}
+
+	return l.health.recoverChan
+}
+
 func (l *RotateLogger) write(v []byte) {
+	if l.degraded {
+		select {
+		case <-l.health.recoverChan:
+			l.replayFallback()
+		default:
+			l.fallback.write(v)
+			return
+		}
+	}
+
 	for {
-		if l.rule.ShallRotate(l.currentSize + int64(len(v))) {
+		if l.rule.ShallRotate(atomic.LoadInt64(&l.currentSize) + int64(len(v))) {
 			if err := l.rotate(); err != nil {
 				log.Println(err)
 			} else {
 				l.rule.MarkRotated()
-				l.currentSize = 0
+				atomic.StoreInt64(&l.currentSize, 0)
 			}
 		}
 
 		if l.fp != nil {
 			_, err := l.fp.Write(v)
 			if err == nil {
-				l.currentSize += int64(len(v))
+				atomic.AddInt64(&l.currentSize, int64(len(v)))
 				return
 			}
 			l.health.ReportError(err)
+		} else {
+			l.health.ReportError(ErrLogFileNotOpen)
+		}
+
+		// 配置了备用输出时不阻塞，恢复后再回放
+		if l.fallback != nil {
+			l.degraded = true
+			l.health.SetDegraded(degradedFallback)
+			l.fallback.write(v)
+			return
 		}
 
-		if !l.health.WaitRecover() {
+		if !l.waitRecover() {
 			return
 		}
 	}
@@ -571,8 +835,6 @@ func (l *RotateLogger) compressUncompressedFiles() {
 		if file == l.filename || strings.HasSuffix(file, gzipExt) {
 			continue
 		}
-		go func(f string) {
-			l.retryCompress <- f
-		}(file)
+		compressor.submit(compressTask{logger: l, file: file})
 	}
 }
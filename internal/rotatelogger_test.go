@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, file string, size int) {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(strings.Repeat("x", size)), defaultFileMode); err != nil {
+		t.Fatalf("创建文件失败: %v", err)
+	}
+}
+
+func TestDailyRotateRule_MaxTotalSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "daily.log")
+
+	writeTestFile(t, logFile, 400)
+	writeTestFile(t, logFile+"-2024-01-01", 300)
+	writeTestFile(t, logFile+"-2024-01-02", 300)
+	writeTestFile(t, logFile+"-2024-01-03", 300)
+
+	rule := DefaultRotateRule(logFile, backupFileDelimiter, 0, false, WithRuleMaxTotalSize(1000))
+	files := rule.OutdatedFiles()
+	sort.Strings(files)
+
+	// 400 + 300*3 = 1300，删除最旧的两个备份后为 700
+	want := []string{logFile + "-2024-01-01", logFile + "-2024-01-02"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("期望删除 %v, 得到 %v", want, files)
+	}
+}
+
+func TestSizeLimitRotateRule_MaxTotalSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "size.log")
+
+	writeTestFile(t, logFile, 100)
+	writeTestFile(t, filepath.Join(tmpDir, "size-2024-01-01T00.00.00.000000000Z.log"), 100)
+	writeTestFile(t, filepath.Join(tmpDir, "size-2024-01-02T00.00.00.000000000Z.log"), 100)
+
+	rule := NewSizeLimitRotateRule(logFile, backupFileDelimiter, 0, 0, 0, false, WithRuleMaxTotalSize(250))
+	files := rule.OutdatedFiles()
+	if len(files) != 1 || !strings.Contains(files[0], "2024-01-01") {
+		t.Errorf("期望只删除最旧的备份, 得到 %v", files)
+	}
+
+	rule = NewSizeLimitRotateRule(logFile, backupFileDelimiter, 0, 0, 0, false)
+	if files := rule.OutdatedFiles(); len(files) != 0 {
+		t.Errorf("未设置总大小限制时不应该删除文件, 得到 %v", files)
+	}
+}
+
+func TestDailyRotateRule_MaxAge(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "age.log")
+
+	oldFile := logFile + "-2099-01-01" // 文件名中的日期不参与判断
+	newFile := logFile + "-2000-01-01"
+	writeTestFile(t, oldFile, 10)
+	writeTestFile(t, newFile, 10)
+
+	past := time.Now().Add(-3 * time.Hour)
+	if err := os.Chtimes(oldFile, past, past); err != nil {
+		t.Fatalf("修改文件时间失败: %v", err)
+	}
+
+	rule := DefaultRotateRule(logFile, backupFileDelimiter, 0, false, WithRuleMaxAge(2*time.Hour))
+	files := rule.OutdatedFiles()
+	if len(files) != 1 || files[0] != oldFile {
+		t.Errorf("期望删除 %s, 得到 %v", oldFile, files)
+	}
+
+	// 未设置 maxAge 时按保留天数计算
+	rule = DefaultRotateRule(logFile, backupFileDelimiter, 1, false)
+	if files := rule.OutdatedFiles(); len(files) != 0 {
+		t.Errorf("期望不删除文件, 得到 %v", files)
+	}
+}
+
+func TestRotateLogger_RetentionDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "plan.log")
+	backups := []string{
+		filepath.Join(tmpDir, "plan-2024-01-01T00.00.00.000000000Z.log"),
+		filepath.Join(tmpDir, "plan-2024-01-02T00.00.00.000000000Z.log"),
+		filepath.Join(tmpDir, "plan-2024-01-03T00.00.00.000000000Z.log"),
+	}
+	for _, f := range backups {
+		writeTestFile(t, f, 10)
+	}
+
+	logger, err := NewLogger(logFile, NewSizeLimitRotateRule(logFile, backupFileDelimiter, 0, 1, 2, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	items := logger.planRetention()
+	if len(items) != 1 || items[0].File != backups[0] || items[0].Reason != RetentionReasonCount {
+		t.Fatalf("期望因数量超限删除 %s, 得到 %+v", backups[0], items)
+	}
+
+	var deleted []RetentionItem
+	SetRetentionHandler(func(item RetentionItem) {
+		deleted = append(deleted, item)
+	})
+	defer SetRetentionHandler(nil)
+
+	SetRetentionDryRun(true)
+	logger.maybeDeleteOutdatedFiles()
+	SetRetentionDryRun(false)
+	if _, err := os.Stat(backups[0]); err != nil {
+		t.Errorf("演练模式不应该删除文件: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("演练模式不应该触发删除回调, 得到 %+v", deleted)
+	}
+
+	logger.maybeDeleteOutdatedFiles()
+	if _, err := os.Stat(backups[0]); !os.IsNotExist(err) {
+		t.Errorf("文件应该被删除: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].File != backups[0] {
+		t.Errorf("期望删除回调一次, 得到 %+v", deleted)
+	}
+}
+
+func TestRotateLogger_RotateAndReopen(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "manual.log")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Write([]byte("before rotate\n"))
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate 失败: %v", err)
+	}
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate 失败: %v", err)
+	}
+
+	backups, _ := filepath.Glob(logFile + backupFileDelimiter + "*")
+	if len(backups) != 2 {
+		t.Fatalf("同一天多次轮转不应该覆盖备份, 得到 %v", backups)
+	}
+
+	// 模拟 logrotate 移走文件后通知重新打开
+	moved := filepath.Join(tmpDir, "moved.log")
+	if err := os.Rename(logFile, moved); err != nil {
+		t.Fatalf("移动文件失败: %v", err)
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Reopen 失败: %v", err)
+	}
+
+	logger.Write([]byte("after reopen\n"))
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Reopen 失败: %v", err)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if string(content) != "after reopen\n" {
+		t.Errorf("期望新文件只包含重新打开后的日志, 得到 %q", content)
+	}
+}
+
+func TestRotateLogger_Flush(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "flush.log")
+
+	logger, err := NewLogger(logFile, DefaultRotateRule(logFile, backupFileDelimiter, 0, false), false)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 100; i++ {
+		logger.Write([]byte("line\n"))
+	}
+	if err := FlushAll(time.Second); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if strings.Count(string(content), "line\n") != 100 {
+		t.Errorf("Flush 返回后所有日志应该已经写入文件, 得到 %d 行", strings.Count(string(content), "line\n"))
+	}
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"io"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// routes 保存按模块和级别分流的日志输出
+var routes atomic.Value
+
+type (
+	// RouteConf 是一条分流规则，匹配的日志写入 `<ServiceName>_<Name>.log`，代替服务日志，控制台和错误日志照常写入
+	RouteConf struct {
+		// Name 表示日志文件名中的名称
+		Name string
+		// Modules 表示匹配的模块名，为空时匹配所有模块
+		Modules []string `json:",optional"`
+		// Levels 表示匹配的日志级别，为空时匹配所有级别
+		Levels []string `json:",optional"`
+		// StreamConf 表示该日志文件单独的轮转和保留配置
+		StreamConf
+	}
+
+	route struct {
+		modules map[string]PlaceholderType
+		levels  map[uint32]PlaceholderType
+		out     io.WriteCloser
+	}
+)
+
+// setupRoutes 为每条分流规则创建单独轮转的日志输出
+func setupRoutes(c LogConf) error {
+	var rs []*route
+	for _, conf := range c.Routes {
+		if len(conf.Name) == 0 {
+			closeRoutes(rs)
+			return ErrRouteNameNotSet
+		}
+
+		file := path.Join(c.ServerLogDir, c.ServiceName+"_"+conf.Name+".log")
+		out, err := createOutput(file, conf.StreamConf.options()...)
+		if err != nil {
+			closeRoutes(rs)
+			return err
+		}
+
+		r := &route{out: out}
+		if len(conf.Modules) > 0 {
+			r.modules = make(map[string]PlaceholderType, len(conf.Modules))
+			for _, module := range conf.Modules {
+				r.modules[module] = Placeholder
+			}
+		}
+		if len(conf.Levels) > 0 {
+			r.levels = make(map[uint32]PlaceholderType, len(conf.Levels))
+			for _, tag := range conf.Levels {
+				if level, ok := parseLevel(strings.TrimSpace(tag)); ok {
+					r.levels[level] = Placeholder
+				}
+			}
+		}
+		rs = append(rs, r)
+	}
+
+	routes.Store(rs)
+	return nil
+}
+
+// resetRoutes 关闭所有分流输出
+func resetRoutes() error {
+	rs, _ := routes.Swap([]*route(nil)).([]*route)
+	return closeRoutes(rs)
+}
+
+func closeRoutes(rs []*route) error {
+	var be BatchError
+	for _, r := range rs {
+		be.Add(r.out.Close())
+	}
+	return be.Err()
+}
+
+// matchRoute 返回第一条匹配模块和级别的分流输出
+func matchRoute(module string, level uint32) io.Writer {
+	rs, _ := routes.Load().([]*route)
+	for _, r := range rs {
+		if r.match(module, level) {
+			return r.out
+		}
+	}
+
+	return nil
+}
+
+func (r *route) match(module string, level uint32) bool {
+	if r.modules != nil {
+		if _, ok := r.modules[module]; !ok {
+			return false
+		}
+	}
+	if r.levels != nil {
+		if _, ok := r.levels[level]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureServerLog 将写入器替换为写入内存缓冲区的服务日志文件写入器
+func captureServerLog(t *testing.T, level uint32) *bytes.Buffer {
+	t.Helper()
+
+	buf := captureOutput(t, level)
+	lw := newLogWriter(log.New(buf, "", flags))
+	SetWriter(&concreteWriter{serverLog: lw, managerLog: lw, file: true})
+	return buf
+}
+
+func TestRoutes(t *testing.T) {
+	server := captureServerLog(t, InfoLevel)
+
+	tmpDir := t.TempDir()
+	err := setupRoutes(LogConf{
+		ServiceName:  "svc",
+		ServerLogDir: tmpDir,
+		Routes: []RouteConf{
+			{Name: "billing", Modules: []string{"billing"}, StreamConf: StreamConf{MaxBackups: 3}},
+			{Name: "warn", Levels: []string{"WAR"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建分流输出失败: %v", err)
+	}
+	defer resetRoutes()
+
+	billing := WithModuleName("billing")
+	billing.Info("charged")
+	billing.Debug("below level")
+	WithModuleName("order").Warn("slow order")
+	WithModuleName("order").Info("order created")
+
+	if err := FlushAll(time.Second); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(tmpDir, "svc_"+name+".log"))
+		if err != nil {
+			t.Fatalf("读取分流日志失败: %v", err)
+		}
+		return string(content)
+	}
+
+	if content := read("billing"); !strings.Contains(content, "[billing] charged") ||
+		strings.Contains(content, "below level") {
+		t.Errorf("billing 模块的日志应该写入单独的文件, 得到 %q", content)
+	}
+	if content := read("warn"); !strings.Contains(content, "[order] slow order") {
+		t.Errorf("WAR 日志应该写入单独的文件, 得到 %q", content)
+	}
+	if content := server.String(); content == "" || strings.Contains(content, "charged") ||
+		strings.Contains(content, "slow order") || !strings.Contains(content, "order created") {
+		t.Errorf("只有未匹配规则的日志写入服务日志, 得到 %q", content)
+	}
+}
+
+func TestRoutes_ConsoleAndErrorLog(t *testing.T) {
+	server := captureServerLog(t, InfoLevel)
+	var console bytes.Buffer
+	AddWriter(NewWriter(&console))
+	var errLog bufferCloser
+	AddWriter(newErrorWriter(&errLog))
+
+	tmpDir := t.TempDir()
+	err := setupRoutes(LogConf{
+		ServiceName:  "svc",
+		ServerLogDir: tmpDir,
+		Routes:       []RouteConf{{Name: "billing", Modules: []string{"billing"}}},
+	})
+	if err != nil {
+		t.Fatalf("创建分流输出失败: %v", err)
+	}
+	defer resetRoutes()
+
+	billing := WithModuleName("billing")
+	billing.Info("charged")
+	billing.Error("charge failed")
+
+	if err := FlushAll(time.Second); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "svc_billing.log"))
+	if err != nil {
+		t.Fatalf("读取分流日志失败: %v", err)
+	}
+	for _, want := range []string{"charged", "charge failed"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("分流日志缺少 %q: %q", want, content)
+		}
+		if !strings.Contains(console.String(), want) {
+			t.Errorf("控制台缺少分流的日志 %q: %q", want, console.String())
+		}
+		if strings.Contains(server.String(), want) {
+			t.Errorf("服务日志不应包含分流的日志 %q: %q", want, server.String())
+		}
+	}
+	if errs := errLog.String(); !strings.Contains(errs, "charge failed") || strings.Contains(errs, "charged") {
+		t.Errorf("错误日志应该只包含分流的 ERR 日志, 得到 %q", errs)
+	}
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+const (
+	// SanitizeEscape 将消息中的换行和其他控制字符转义为 `\n`、`\x1b` 等，每条日志只占一行
+	SanitizeEscape uint32 = iota
+	// SanitizeMultiline 保留换行，不以空白开头的后续行缩进，解析时可以按缩进归入同一条日志
+	SanitizeMultiline
+	// SanitizeOff 原样写入消息
+	SanitizeOff
+)
+
+var (
+	// sanitizeMode 是纯文本编码时消息的处理方式，默认转义
+	sanitizeMode = SanitizeEscape
+	// stripAnsi 为 1 时删除消息中的 ANSI 转义序列，否则转义
+	stripAnsi uint32
+)
+
+// SetSanitizeMode 设置纯文本编码时消息中控制字符的处理方式，防止伪造日志行和终端转义序列
+// strip 为 true 时删除 ANSI 转义序列而不是转义
+func SetSanitizeMode(mode uint32, strip bool) {
+	atomic.StoreUint32(&sanitizeMode, mode)
+	if strip {
+		atomic.StoreUint32(&stripAnsi, 1)
+	} else {
+		atomic.StoreUint32(&stripAnsi, 0)
+	}
+}
+
+// sanitize 按当前的处理方式转义消息中的控制字符
+func sanitize(msg string) string {
+	mode := atomic.LoadUint32(&sanitizeMode)
+	if mode == SanitizeOff || !hasControl(msg) {
+		return msg
+	}
+
+	strip := atomic.LoadUint32(&stripAnsi) == 1
+	var buf strings.Builder
+	buf.Grow(len(msg) + 16)
+	for i := 0; i < len(msg); {
+		r, size := utf8.DecodeRuneInString(msg[i:])
+		switch {
+		case r == '\x1b' && strip:
+			size = ansiLength(msg[i:])
+		case r == '\n' && mode == SanitizeMultiline:
+			buf.WriteByte('\n')
+			if next := i + 1; next < len(msg) && msg[next] != ' ' && msg[next] != '\t' {
+				buf.WriteString(detailIndent)
+			}
+		case r == '\r' && mode == SanitizeMultiline && strings.HasPrefix(msg[i+1:], "\n"):
+			// 多行模式下 CRLF 按换行处理
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case isControl(r):
+			if r < utf8.RuneSelf {
+				buf.WriteString(fmt.Sprintf(`\x%02x`, r))
+			} else {
+				buf.WriteString(fmt.Sprintf(`\u%04x`, r))
+			}
+		default:
+			buf.WriteString(msg[i : i+size])
+		}
+		i += size
+	}
+
+	return buf.String()
+}
+
+func hasControl(msg string) bool {
+	for _, r := range msg {
+		if isControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isControl 返回是否需要处理的控制字符，制表符保持不变
+func isControl(r rune) bool {
+	return (r < 0x20 && r != '\t') || r == 0x7f || (r >= 0x80 && r <= 0x9f)
+}
+
+// ansiLength 返回以 ESC 开头的 ANSI 转义序列的长度，包括 CSI 序列 `ESC [ ... final` 和 OSC 序列
+func ansiLength(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	default:
+		return 2
+	}
+}
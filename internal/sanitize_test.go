@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	defer SetSanitizeMode(SanitizeEscape, false)
+
+	tests := []struct {
+		mode  uint32
+		strip bool
+		input string
+		want  string
+	}{
+		{SanitizeEscape, false, "plain text\twith tab", "plain text\twith tab"},
+		{SanitizeEscape, false, "user\n[ERR] 2024-01-01 forged", `user\n[ERR] 2024-01-01 forged`},
+		{SanitizeEscape, false, "a\r\nb\x00c", `a\r\nb\x00c`},
+		{SanitizeEscape, false, "\x1b[31mred\x1b[0m", `\x1b[31mred\x1b[0m`},
+		{SanitizeEscape, true, "\x1b[31mred\x1b[0m\x1b]0;title\a", "red"},
+		{SanitizeEscape, false, "c1\u0085end", `c1\u0085end`},
+		{SanitizeMultiline, false, "first\nsecond\n    indented", "first\n    second\n    indented"},
+		{SanitizeMultiline, false, "crlf\r\n[ERR] forged", "crlf\n    [ERR] forged"},
+		{SanitizeOff, false, "raw\nline", "raw\nline"},
+	}
+
+	for _, test := range tests {
+		SetSanitizeMode(test.mode, test.strip)
+		if got := sanitize(test.input); got != test.want {
+			t.Errorf("sanitize(%q) mode %d = %q, want %q", test.input, test.mode, got, test.want)
+		}
+	}
+}
+
+func TestSanitizePlainText(t *testing.T) {
+	text := GetOutputStringFormatted(LevelInfo, "login user=bob\n[ERR] 2024-01-01 00:00:00 forged")
+	if strings.Count(text, "\n") != 1 || !strings.HasSuffix(text, "\n") {
+		t.Errorf("消息中的换行应被转义, 得到 %q", text)
+	}
+}
+
+func TestSanitizeInternalLog(t *testing.T) {
+	buf := captureOutput(t, InfoLevel)
+
+	Infof("delete outdated or limited file: %s, reason: %s", "/logs/a.log\n[ERR] forged", "age")
+	Errorf("compress error: %s", "bad\x1b[31m")
+
+	content := buf.String()
+	if strings.Count(content, "\n") != 2 || strings.Contains(content, "\x1b") {
+		t.Errorf("库内部日志中的控制字符应被转义, 得到 %q", content)
+	}
+	if !strings.Contains(content, `a.log\n[ERR] forged`) {
+		t.Errorf("库内部日志中的换行应被转义, 得到 %q", content)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build !windows
+
+package internal
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var signalWatcher struct {
+	lock sync.Mutex
+	ch   chan os.Signal
+	stop chan PlaceholderType
+}
+
+// WatchSignals 监听 SIGHUP 和 SIGUSR1，SIGHUP 重新打开所有日志文件，SIGUSR1 轮转所有日志文件
+func WatchSignals() {
+	signalWatcher.lock.Lock()
+	defer signalWatcher.lock.Unlock()
+
+	if signalWatcher.ch != nil {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	stop := make(chan PlaceholderType)
+	signal.Notify(ch, syscall.SIGHUP, syscall.SIGUSR1)
+	signalWatcher.ch = ch
+	signalWatcher.stop = stop
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				handleSignal(sig)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopWatchSignals 停止监听信号
+func StopWatchSignals() {
+	signalWatcher.lock.Lock()
+	defer signalWatcher.lock.Unlock()
+
+	if signalWatcher.ch == nil {
+		return
+	}
+
+	signal.Stop(signalWatcher.ch)
+	close(signalWatcher.stop)
+	signalWatcher.ch = nil
+	signalWatcher.stop = nil
+}
+
+func handleSignal(sig os.Signal) {
+	var err error
+	switch sig {
+	case syscall.SIGHUP:
+		err = ReopenAll()
+	case syscall.SIGUSR1:
+		err = RotateAll()
+	default:
+		return
+	}
+
+	if err != nil {
+		Errorf("failed to handle signal %s: %v", sig, err)
+	} else {
+		Infof("handled signal %s", sig)
+	}
+}
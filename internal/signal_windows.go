@@ -0,0 +1,9 @@
+//go:build windows
+
+package internal
+
+// WatchSignals windows 不支持 SIGHUP/SIGUSR1，忽略
+func WatchSignals() {}
+
+// StopWatchSignals windows 不支持 SIGHUP/SIGUSR1，忽略
+func StopWatchSignals() {}
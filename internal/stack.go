@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	maxStackDepth = 32
+	qlogPackage   = "github.com/FortuneW/qlog"
+	detailIndent  = "    "
+)
+
+// stackLevel 是附加调用栈的最低日志级别，默认不附加
+var stackLevel uint32 = DisableLevel
+
+// stackOption 是单次调用附加调用栈的标记参数
+type stackOption struct{}
+
+// WithStack 返回一个标记参数，作为 Error/Errorf 的参数传入时附加调用栈和错误原因链，标记本身不会输出
+func WithStack() any {
+	return stackOption{}
+}
+
+// SetStackLevel 设置附加调用栈和错误原因链的最低日志级别，DisableLevel 表示不附加
+func SetStackLevel(level uint32) {
+	atomic.StoreUint32(&stackLevel, level)
+}
+
+func shallStack(level uint32) bool {
+	return levelAtLeast(level, atomic.LoadUint32(&stackLevel))
+}
+
+// detailOption 是单次调用追加多行内容的标记参数
+type detailOption string
+
+// WithDetail 返回一个标记参数，作为 Error/Errorf 的参数传入时在转义后的消息之后另起一行原样追加 detail，
+// 用于崩溃时所有协程的调用栈这类本身就是多行的内容，标记本身不会输出
+func WithDetail(detail string) any {
+	return detailOption(detail)
+}
+
+// errorOptions 是从参数中取出的 WithStack 和 WithDetail 标记
+type errorOptions struct {
+	stack  bool
+	detail string
+}
+
+// splitOptions 移除参数中的 WithStack 和 WithDetail 标记，返回剩余参数以及标记
+func splitOptions(v []any) ([]any, errorOptions) {
+	var opts errorOptions
+	var found bool
+	for _, arg := range v {
+		switch o := arg.(type) {
+		case stackOption:
+			opts.stack, found = true, true
+		case detailOption:
+			opts.detail, found = string(o), true
+		}
+	}
+	if !found {
+		return v, opts
+	}
+
+	args := make([]any, 0, len(v)-1)
+	for _, arg := range v {
+		switch arg.(type) {
+		case stackOption, detailOption:
+		default:
+			args = append(args, arg)
+		}
+	}
+	return args, opts
+}
+
+// SplitDetail 移除参数中的 WithStack 和 WithDetail 标记，返回剩余参数和 WithDetail 的内容，用于不附加调用栈的子进程日志
+func SplitDetail(v []any) ([]any, string) {
+	args, opts := splitOptions(v)
+	return args, opts.detail
+}
+
+// withErrorDetail 转义调用方的消息，再追加 WithDetail 的内容，
+// 达到 stackLevel 或者传入了 WithStack 标记时追加错误原因链和调用栈，追加的内容保留换行
+func withErrorDetail(level uint32, msg string, args []any, opts errorOptions) string {
+	msg = escapeMessage(msg)
+	if len(opts.detail) > 0 {
+		msg += "\n" + opts.detail
+	}
+	if !opts.stack && !shallStack(level) {
+		return msg
+	}
+
+	return appendErrorDetail(msg, args)
+}
+
+// appendErrorDetail 在消息后追加参数中错误的原因链和调用栈
+func appendErrorDetail(msg string, args []any) string {
+	var buf strings.Builder
+	buf.WriteString(msg)
+
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			writeCauses(&buf, err)
+		}
+	}
+
+	buf.WriteString("\nstack:")
+	for _, frame := range callerStack() {
+		buf.WriteByte('\n')
+		buf.WriteString(detailIndent)
+		buf.WriteString(frame)
+	}
+
+	return buf.String()
+}
+
+// writeCauses 沿着 Unwrap 链输出错误的每一个原因，errors.Join 的多个原因按层级缩进
+func writeCauses(buf *strings.Builder, err error) {
+	causes := unwrapErrors(err)
+	if len(causes) == 0 {
+		return
+	}
+
+	buf.WriteString("\ncaused by:")
+	for _, cause := range causes {
+		writeCause(buf, cause, 1)
+	}
+}
+
+func writeCause(buf *strings.Builder, err error, depth int) {
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Repeat(detailIndent, depth))
+	buf.WriteString(fmt.Sprintf("%T: %s", err, sanitize(encodeError(err))))
+
+	for _, cause := range unwrapErrors(err) {
+		writeCause(buf, cause, depth+1)
+	}
+}
+
+func unwrapErrors(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	}
+
+	return nil
+}
+
+// callerStack 返回调用日志的位置开始的调用栈，跳过 qlog 内部的调用
+func callerStack() []string {
+	pcs := make([]uintptr, maxStackDepth+16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []string
+	for {
+		frame, more := frames.Next()
+		if !isQlogFrame(frame) {
+			stack = append(stack, fmt.Sprintf("%s %s", frame.Function, prettyCaller(frame.File, frame.Line)))
+			if len(stack) >= maxStackDepth {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
+
+func isQlogFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	return strings.HasPrefix(frame.Function, qlogPackage+".") ||
+		strings.HasPrefix(frame.Function, qlogPackage+"/internal.")
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRichLogger_ErrorWithStack(t *testing.T) {
+	buf := captureOutput(t, TraceLevel)
+
+	base := errors.New("disk full")
+	err := fmt.Errorf("save failed: %w", errors.Join(base, errors.New("retry exhausted")))
+
+	logger := WithModuleName("stack")
+	logger.Errorf("request failed: %v", err)
+	if strings.Contains(buf.String(), "stack:") {
+		t.Errorf("未开启时不应该附加调用栈, 得到 %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.Errorf("request failed: %v", err, WithStack())
+	content := buf.String()
+	if strings.Contains(content, "EXTRA") || strings.Contains(content, "stackOption") {
+		t.Errorf("标记参数不应该被输出, 得到 %q", content)
+	}
+	for _, want := range []string{
+		"caused by:",
+		"\n        *errors.errorString: disk full",
+		"\n        *errors.errorString: retry exhausted",
+		"stack:",
+		"TestRichLogger_ErrorWithStack",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("期望包含 %q, 得到 %q", want, content)
+		}
+	}
+	if strings.Contains(content, "richLogger") {
+		t.Errorf("调用栈不应该包含 qlog 内部调用, 得到 %q", content)
+	}
+
+	buf.Reset()
+	SetStackLevel(ErrorLevel)
+	defer SetStackLevel(DisableLevel)
+	logger.Error(base)
+	if !strings.Contains(buf.String(), "stack:") || strings.Contains(buf.String(), "caused by:") {
+		t.Errorf("达到级别时应该附加调用栈, 没有原因时不输出原因链, 得到 %q", buf.String())
+	}
+}
+
+func TestRichLogger_DetailKeepsLines(t *testing.T) {
+	// 默认的转义模式下只转义调用方的消息，原因链、调用栈和 WithDetail 的内容保留换行
+	buf := captureOutput(t, TraceLevel)
+
+	err := fmt.Errorf("save failed: %w", errors.New("disk\nfull"))
+	logger := WithModuleName("stack")
+	logger.Errorf("bad input %s: %v", "a\n[ERR] forged", err, WithStack())
+
+	content := buf.String()
+	for _, want := range []string{
+		`bad input a\n[ERR] forged`,
+		"\ncaused by:\n    *errors.errorString: disk\\nfull",
+		"\nstack:\n    ",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("期望包含 %q, 得到 %q", want, content)
+		}
+	}
+
+	buf.Reset()
+	logger.Error("panic: boom", WithDetail("goroutine 1 [running]:\nmain.main()"))
+	if !strings.Contains(buf.String(), "panic: boom\ngoroutine 1 [running]:\nmain.main()\n") ||
+		strings.Contains(buf.String(), "detailOption") {
+		t.Errorf("WithDetail 的内容应原样另起一行追加, 得到 %q", buf.String())
+	}
+}
+
+func TestRichLogger_TruncateKeepsDetail(t *testing.T) {
+	// 超过长度时只截断调用方的消息，原因链、调用栈和 WithDetail 的内容完整保留
+	buf := captureOutput(t, TraceLevel)
+	old := atomic.SwapUint32(&maxContentLength, 16)
+	defer atomic.StoreUint32(&maxContentLength, old)
+
+	logger := WithModuleName("stack")
+	err := fmt.Errorf("save failed: %w", errors.New("disk full"))
+	logger.Errorf("request failed with a very long message: %v", err, WithStack())
+	content := buf.String()
+	for _, want := range []string{
+		"request failed w...(truncated",
+		"\ncaused by:\n    *errors.errorString: disk full",
+		"TestRichLogger_TruncateKeepsDetail",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("期望包含 %q, 得到 %q", want, content)
+		}
+	}
+	if strings.Count(content, "truncated") != 1 {
+		t.Errorf("只应该截断调用方的消息, 得到 %q", content)
+	}
+
+	buf.Reset()
+	logger.Error("panic: boom boom boom", WithDetail("goroutine 1 [running]:\nmain.main()"))
+	if !strings.HasSuffix(buf.String(), "...(truncated 5 bytes)\ngoroutine 1 [running]:\nmain.main()\n") {
+		t.Errorf("WithDetail 的内容不应该被截断, 得到 %q", buf.String())
+	}
+}
+
+func TestRichLogger_PanicAndFatal(t *testing.T) {
+	buf := captureOutput(t, ErrorLevel)
+
+	var exitCode int
+	oldExit := exitFunc
+	exitFunc = func(code int) { exitCode = code }
+	defer func() { exitFunc = oldExit }()
+
+	logger := WithModuleName("fatal")
+	logger.Fatalf("cannot start: %s", "port in use")
+	if exitCode != 1 || !strings.Contains(buf.String(), "[FTL]") ||
+		!strings.Contains(buf.String(), "[fatal] cannot start: port in use") {
+		t.Errorf("Fatal 应该记录日志后退出, 退出码 %d, 得到 %q", exitCode, buf.String())
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "broken invariant" {
+				t.Errorf("Panic 应该以原始消息 panic, 得到 %v", r)
+			}
+		}()
+		logger.Panic("broken invariant")
+	}()
+	if !strings.Contains(buf.String(), "[PNC]") {
+		t.Errorf("Panic 应该记录日志, 得到 %q", buf.String())
+	}
+}
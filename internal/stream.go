@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// namedStreams 保存通过 GetStream 获取的命名日志流，首次写入时创建日志文件
+var namedStreams streamRegistry
+
+type (
+	streamRegistry struct {
+		lock     sync.Mutex
+		enabled  bool
+		dir      string
+		service  string
+		confs    map[string]StreamConf
+		outputs  map[string]io.WriteCloser
+		reserved map[string]PlaceholderType
+		failed   map[string]PlaceholderType
+	}
+
+	// StreamLogger 写入命名日志流，与管理日志相同，只包含时间不包含日志级别
+	StreamLogger struct {
+		name string
+	}
+)
+
+// StreamConf 是单个日志流的轮转和保留配置，零值表示使用全局配置
+type StreamConf struct {
+	// Rotation 表示日志轮转规则类型，daily 或 size
+	Rotation string `json:",optional"`
+	// MaxSize 表示单个日志文件的最大大小，单位为MB
+	MaxSize int `json:",optional"`
+	// MaxBackups 表示保留的备份文件数量
+	MaxBackups int `json:",optional"`
+	// KeepDays 表示日志文件保留天数
+	KeepDays int `json:",optional"`
+	// MaxAge 表示日志文件最长保留时长，按文件修改时间判断
+	MaxAge time.Duration `json:",optional"`
+	// MaxTotalSize 表示日志流（备份文件与当前文件）可占用的最大空间，单位为MB
+	MaxTotalSize int `json:",optional"`
+	// Compress 表示是否压缩日志文件，为空时使用全局配置
+	Compress *bool `json:",optional"`
+}
+
+// options 返回覆盖全局配置的选项，用于 createOutput
+func (c StreamConf) options() []LogOption {
+	var opts []LogOption
+	if len(c.Rotation) > 0 {
+		opts = append(opts, WithRotation(c.Rotation))
+	}
+	if c.MaxSize > 0 {
+		opts = append(opts, WithMaxSize(c.MaxSize))
+	}
+	if c.MaxBackups > 0 {
+		opts = append(opts, WithMaxBackups(c.MaxBackups))
+	}
+	if c.KeepDays > 0 {
+		opts = append(opts, WithKeepDays(c.KeepDays))
+	}
+	if c.MaxAge > 0 {
+		opts = append(opts, WithMaxAge(c.MaxAge))
+	}
+	if c.MaxTotalSize > 0 {
+		opts = append(opts, WithMaxTotalSize(c.MaxTotalSize))
+	}
+	if c.Compress != nil {
+		opts = append(opts, WithGzipEnabled(*c.Compress))
+	}
+
+	return opts
+}
+
+// GetStream 返回写入 `<ServiceName>_<name>.log` 的日志流，文件位于 StreamDir 目录下
+// 轮转和保留配置使用 Streams 中同名的配置，未配置的项使用全局配置
+// name 不能包含路径分隔符，也不能与 server、manager、crash、error 以及同目录下的分流规则重名；
+// 名称不合法或者创建文件失败时只输出一次错误，之后该日志流写入访问日志
+// 非文件模式下写入当前的写入器
+func GetStream(name string) *StreamLogger {
+	return &StreamLogger{name: name}
+}
+
+func (l *StreamLogger) Print(args ...any) {
+	l.write(fmt.Sprint(args...))
+}
+
+func (l *StreamLogger) Printf(format string, args ...any) {
+	l.write(fmt.Sprintf(format, args...))
+}
+
+func (l *StreamLogger) write(msg string) {
+	msg, keep := runHooks(AccessLevel, l.name, "", redact(escapeMessage(msg)))
+	if !keep {
+		return
+	}
+
+	if out := namedStreams.output(l.name); out != nil {
+		output(out, levelAccessRecord, msg)
+	} else {
+		getWriter().AccessRecord(msg)
+	}
+}
+
+// setupStreams 记录命名日志流的目录和配置，日志文件在首次写入时创建
+func setupStreams(c LogConf) {
+	namedStreams.lock.Lock()
+	defer namedStreams.lock.Unlock()
+
+	namedStreams.enabled = true
+	namedStreams.dir = c.StreamDir
+	if len(namedStreams.dir) == 0 {
+		namedStreams.dir = c.ServerLogDir
+	}
+	namedStreams.service = c.ServiceName
+	namedStreams.confs = c.Streams
+	namedStreams.outputs = make(map[string]io.WriteCloser)
+	namedStreams.failed = make(map[string]PlaceholderType)
+
+	// 内置日志文件和同目录下的分流规则使用的名称
+	namedStreams.reserved = map[string]PlaceholderType{
+		strings.TrimSuffix(serverFilename, ".log"):  Placeholder,
+		strings.TrimSuffix(managerFilename, ".log"): Placeholder,
+		strings.TrimSuffix(crashFilename, ".log"):   Placeholder,
+		strings.TrimSuffix(errorFilename, ".log"):   Placeholder,
+	}
+	if namedStreams.dir == c.ServerLogDir {
+		for _, route := range c.Routes {
+			namedStreams.reserved[route.Name] = Placeholder
+		}
+	}
+}
+
+// resetStreams 关闭所有命名日志流
+func resetStreams() error {
+	namedStreams.lock.Lock()
+	defer namedStreams.lock.Unlock()
+
+	var be BatchError
+	for _, out := range namedStreams.outputs {
+		be.Add(out.Close())
+	}
+	namedStreams.enabled = false
+	namedStreams.outputs = nil
+	namedStreams.failed = nil
+	return be.Err()
+}
+
+func (r *streamRegistry) output(name string) io.Writer {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.enabled || len(name) == 0 {
+		return nil
+	}
+	if out, ok := r.outputs[name]; ok {
+		return out
+	}
+	if _, ok := r.failed[name]; ok {
+		return nil
+	}
+
+	if err := r.checkName(name); err != nil {
+		r.fail(name, err)
+		return nil
+	}
+
+	file := path.Join(r.dir, r.service+"_"+name+".log")
+	out, err := createOutput(file, r.confs[name].options()...)
+	if err != nil {
+		r.fail(name, err)
+		return nil
+	}
+
+	r.outputs[name] = out
+	return out
+}
+
+func (r *streamRegistry) checkName(name string) error {
+	if strings.ContainsAny(name, `/\`) {
+		return ErrInvalidStreamName
+	}
+	if _, ok := r.reserved[name]; ok {
+		return ErrReservedStreamName
+	}
+
+	return nil
+}
+
+// fail 记录创建失败的日志流，只输出一次错误，之后不再重试
+func (r *streamRegistry) fail(name string, err error) {
+	r.failed[name] = Placeholder
+	Errorf("log stream %q is unavailable, records are written to the access log, error: %v", name, err)
+}
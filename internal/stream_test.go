@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetStream(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupStreams(LogConf{
+		ServiceName:  "svc",
+		ServerLogDir: t.TempDir(),
+		StreamDir:    tmpDir,
+		Streams: map[string]StreamConf{
+			"audit": {MaxBackups: 3},
+		},
+	})
+
+	GetStream("audit").Printf("user %s login", "admin")
+	GetStream("slowquery").Print("select took 3s")
+
+	if err := FlushAll(time.Second); err != nil {
+		t.Fatalf("Flush 失败: %v", err)
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(tmpDir, "svc_"+name+".log"))
+		if err != nil {
+			t.Fatalf("读取日志流失败: %v", err)
+		}
+		return string(content)
+	}
+
+	if content := read("audit"); !strings.Contains(content, "user admin login") ||
+		strings.Contains(content, levelTag(InfoLevel)) {
+		t.Errorf("audit 日志流内容不正确: %q", content)
+	}
+	if content := read("slowquery"); !strings.Contains(content, "select took 3s") {
+		t.Errorf("slowquery 日志流内容不正确: %q", content)
+	}
+
+	if err := resetStreams(); err != nil {
+		t.Fatalf("关闭日志流失败: %v", err)
+	}
+
+	// 关闭后写入当前的写入器
+	buf := captureOutput(t, GetLevel())
+
+	GetStream("audit").Print("after close")
+	if !strings.Contains(buf.String(), "after close") {
+		t.Errorf("关闭后应写入当前写入器: %q", buf.String())
+	}
+}
+
+func TestGetStream_InvalidName(t *testing.T) {
+	serverDir := t.TempDir()
+	setupStreams(LogConf{
+		ServiceName:  "svc",
+		ServerLogDir: serverDir,
+		Routes:       []RouteConf{{Name: "payment"}},
+	})
+	defer resetStreams()
+
+	buf := captureOutput(t, GetLevel())
+	for _, name := range []string{"../escape", `a\b`, "server", "error", "crash", "manager", "payment"} {
+		GetStream(name).Print("record of " + name)
+		GetStream(name).Print("again " + name)
+
+		if n := strings.Count(buf.String(), "is unavailable"); n != 1 {
+			t.Errorf("日志流 %s 应只输出一次错误, 实际 %d 次: %q", name, n, buf.String())
+		}
+		if !strings.Contains(buf.String(), "again "+name) {
+			t.Errorf("日志流 %s 不可用时应写入访问日志: %q", name, buf.String())
+		}
+		buf.Reset()
+	}
+
+	entries, err := os.ReadDir(serverDir)
+	if err != nil {
+		t.Fatalf("读取日志目录失败: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("不合法的日志流不应创建文件: %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(serverDir), "escape.log")); err == nil {
+		t.Error("日志流不应写到日志目录之外")
+	}
+}
+
+func TestStreamConfOptions(t *testing.T) {
+	disabled := false
+	conf := StreamConf{
+		Rotation:   sizeRotationRule,
+		MaxSize:    200,
+		MaxBackups: 20,
+		Compress:   &disabled,
+	}
+
+	o := logOptions{
+		gzipEnabled: true,
+		maxBackups:  5,
+		keepDays:    7,
+	}
+	for _, opt := range conf.options() {
+		opt(&o)
+	}
+
+	if o.rotationRule != sizeRotationRule || o.maxSize != 200 || o.maxBackups != 20 {
+		t.Errorf("日志流配置未覆盖全局配置: %+v", o)
+	}
+	if o.gzipEnabled {
+		t.Error("Compress 为 false 时应关闭压缩")
+	}
+	if o.keepDays != 7 {
+		t.Errorf("未配置的项应使用全局配置, keepDays: %d", o.keepDays)
+	}
+}
@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTraceBufferTraces  = 1000
+	defaultTraceBufferTimeout = time.Minute
+	minTraceEvictInterval     = time.Second
+)
+
+var (
+	traces traceBuffer
+	// traceBuffering 表示是否开启了按链路缓存，避免每条日志都加锁检查
+	traceBuffering uint32
+)
+
+type (
+	// traceBuffer 按 traceId 在内存中缓存低于日志级别的日志
+	// 链路正常结束时丢弃，链路中出现 ERR 时把缓存的日志写入日志文件
+	traceBuffer struct {
+		lock       sync.Mutex
+		maxRecords int
+		maxTraces  int
+		timeout    time.Duration
+		traces     map[string]*bufferedTrace
+		stop       chan PlaceholderType
+	}
+
+	bufferedTrace struct {
+		records  []string
+		dropped  int
+		failed   bool
+		lastSeen time.Time
+	}
+)
+
+// EnableTraceBuffer 开启按链路缓存调试日志，maxRecords 为每个链路最多缓存的条数，
+// maxTraces 为同时缓存的链路数，timeout 为链路未结束时的最长缓存时间
+func EnableTraceBuffer(maxRecords, maxTraces int, timeout time.Duration) {
+	if maxRecords <= 0 {
+		return
+	}
+	if maxTraces <= 0 {
+		maxTraces = defaultTraceBufferTraces
+	}
+	if timeout <= 0 {
+		timeout = defaultTraceBufferTimeout
+	}
+
+	traces.lock.Lock()
+	defer traces.lock.Unlock()
+
+	traces.maxRecords = maxRecords
+	traces.maxTraces = maxTraces
+	traces.timeout = timeout
+	if traces.traces == nil {
+		traces.traces = make(map[string]*bufferedTrace)
+	}
+	if traces.stop == nil {
+		traces.stop = make(chan PlaceholderType)
+		go traces.evictLoop(traces.stop, timeout)
+	}
+	atomic.StoreUint32(&traceBuffering, 1)
+}
+
+// DisableTraceBuffer 关闭按链路缓存调试日志，丢弃所有缓存
+func DisableTraceBuffer() {
+	traces.lock.Lock()
+	defer traces.lock.Unlock()
+
+	atomic.StoreUint32(&traceBuffering, 0)
+	if traces.stop != nil {
+		close(traces.stop)
+		traces.stop = nil
+	}
+	traces.maxRecords = 0
+	traces.traces = nil
+}
+
+// FinishTrace 标记链路结束，丢弃链路中缓存的日志
+func FinishTrace(traceId string) {
+	traces.lock.Lock()
+	defer traces.lock.Unlock()
+	delete(traces.traces, traceId)
+}
+
+// shallBuffer 返回给定级别的日志是否需要按链路缓存，低于全局日志级别的日志不会写入日志文件
+func shallBuffer(level uint32) bool {
+	return atomic.LoadUint32(&traceBuffering) == 1 && !levelAtLeast(level, effectiveLevel())
+}
+
+// buffer 缓存一条低于日志级别的日志，链路已经出错时直接写入
+func (b *traceBuffer) buffer(traceId string, level uint32, msg string) {
+	line := formatOutput(levelTag(level), msg)
+
+	b.lock.Lock()
+	if b.maxRecords <= 0 {
+		b.lock.Unlock()
+		return
+	}
+
+	trace, ok := b.traces[traceId]
+	if !ok {
+		if len(b.traces) >= b.maxTraces {
+			b.evictOldestLocked()
+		}
+		trace = &bufferedTrace{}
+		b.traces[traceId] = trace
+	}
+	trace.lastSeen = time.Now()
+
+	if trace.failed {
+		b.lock.Unlock()
+		writeBuffered(getWriter(), line)
+		return
+	}
+
+	trace.records = append(trace.records, line)
+	if len(trace.records) > b.maxRecords {
+		trace.records[0] = ""
+		trace.records = trace.records[1:]
+		trace.dropped++
+	}
+	b.lock.Unlock()
+}
+
+// flush 链路中出现错误时，将缓存的日志写入日志文件，之后该链路的日志不再缓存
+func (b *traceBuffer) flush(traceId string) {
+	b.lock.Lock()
+	if b.maxRecords <= 0 {
+		b.lock.Unlock()
+		return
+	}
+
+	trace, ok := b.traces[traceId]
+	if !ok {
+		// 还没有缓存的日志，也需要标记出错，之后的日志直接写入
+		if len(b.traces) >= b.maxTraces {
+			b.evictOldestLocked()
+		}
+		trace = &bufferedTrace{}
+		b.traces[traceId] = trace
+	}
+	if trace.failed {
+		trace.lastSeen = time.Now()
+		b.lock.Unlock()
+		return
+	}
+
+	records, dropped := trace.records, trace.dropped
+	trace.records = nil
+	trace.dropped = 0
+	trace.failed = true
+	trace.lastSeen = time.Now()
+	b.lock.Unlock()
+
+	w := getWriter()
+	if dropped > 0 {
+		w.Warn(fmt.Sprintf("[qlog] [%s] %d buffered records dropped", traceId, dropped))
+	}
+	for _, line := range records {
+		writeBuffered(w, line)
+	}
+}
+
+func (b *traceBuffer) evictOldestLocked() {
+	var oldestId string
+	var oldest time.Time
+	for id, trace := range b.traces {
+		if len(oldestId) == 0 || trace.lastSeen.Before(oldest) {
+			oldestId = id
+			oldest = trace.lastSeen
+		}
+	}
+	delete(b.traces, oldestId)
+}
+
+func (b *traceBuffer) evictLoop(stop chan PlaceholderType, timeout time.Duration) {
+	interval := timeout / 2
+	if interval < minTraceEvictInterval {
+		interval = minTraceEvictInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.evictExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// evictExpired 丢弃超时未结束的链路
+func (b *traceBuffer) evictExpired() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	boundary := time.Now().Add(-b.timeout)
+	for id, trace := range b.traces {
+		if trace.lastSeen.Before(boundary) {
+			delete(b.traces, id)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceBuffer_FlushOnError(t *testing.T) {
+	buf := captureOutput(t, InfoLevel)
+
+	EnableTraceBuffer(2, 10, time.Minute)
+	defer DisableTraceBuffer()
+
+	logger := WithModuleName("trace")
+	ok := logger.WithTraceId("ok")
+	ok.Debug("ok debug")
+	ok.Info("ok info")
+	FinishTrace("ok")
+
+	failed := logger.WithTraceId("failed")
+	failed.Trace("step 1")
+	failed.Debug("step 2")
+	failed.Debug("step 3")
+	failed.Error("boom")
+	failed.Debug("after error")
+
+	content := buf.String()
+	if strings.Contains(content, "ok debug") || !strings.Contains(content, "ok info") {
+		t.Errorf("正常结束的链路不应该写入缓存的日志, 得到 %q", content)
+	}
+	if strings.Contains(content, "step 1") || !strings.Contains(content, "1 buffered records dropped") {
+		t.Errorf("超过条数上限的日志应该被丢弃, 得到 %q", content)
+	}
+
+	step2 := strings.Index(content, "[DEB]")
+	boom := strings.Index(content, "boom")
+	after := strings.Index(content, "after error")
+	if step2 < 0 || boom < step2 || after < boom {
+		t.Errorf("缓存的日志应该在错误日志之前写入, 之后的日志直接写入, 得到 %q", content)
+	}
+
+	traces.lock.Lock()
+	count := len(traces.traces)
+	traces.lock.Unlock()
+	if count != 1 {
+		t.Errorf("期望只剩出错的链路, 得到 %d", count)
+	}
+}
+
+func TestTraceBuffer_Eviction(t *testing.T) {
+	oldLevel := GetLevel()
+	SetLevel(InfoLevel)
+	defer SetLevel(oldLevel)
+
+	EnableTraceBuffer(10, 2, time.Minute)
+	defer DisableTraceBuffer()
+
+	logger := WithModuleName("trace")
+	logger.WithTraceId("a").Debug("a")
+	logger.WithTraceId("b").Debug("b")
+	logger.WithTraceId("c").Debug("c")
+
+	traces.lock.Lock()
+	_, hasA := traces.traces["a"]
+	count := len(traces.traces)
+	traces.traces["b"].lastSeen = time.Now().Add(-2 * time.Minute)
+	traces.lock.Unlock()
+	if hasA || count != 2 {
+		t.Errorf("超过链路数上限时应该丢弃最久未写入的链路, 得到 %d", count)
+	}
+
+	traces.evictExpired()
+	traces.lock.Lock()
+	_, hasB := traces.traces["b"]
+	traces.lock.Unlock()
+	if hasB {
+		t.Errorf("超时的链路应该被丢弃")
+	}
+}
@@ -14,6 +14,10 @@ const (
 	WarnLevel
 	// ErrorLevel includes errors
 	ErrorLevel
+	// PanicLevel includes panics and fatals, logs then panics
+	PanicLevel
+	// FatalLevel includes fatals, logs then exits the process
+	FatalLevel
 	// DisableLevel doesn't log any messages
 	DisableLevel = 0xff
 
@@ -22,9 +26,14 @@ const (
 	LevelWarn    = "WAR"
 	LevelDebug   = "DEB"
 	LevelTrace   = "TRA"
+	LevelPanic   = "PNC"
+	LevelFatal   = "FTL"
 	LevelDisable = "OFF"
 
 	levelAccessRecord = "access"
+
+	// AccessLevel 是访问日志和命名日志流传给日志钩子的级别，不参与级别过滤
+	AccessLevel uint32 = 0xfe
 )
 
 const (
@@ -33,9 +42,14 @@ const (
 
 	managerFilename = "manager.log"
 	serverFilename  = "server.log"
+	crashFilename   = "crash.log"
+	errorFilename   = "error.log"
 
 	fileMode = "file"
 
+	sanitizeMultilineMode = "multiline"
+	sanitizeOffMode       = "off"
+
 	backupFileDelimiter = "-"
 	nilAngleString      = "<nil>"
 	flags               = 0x0
@@ -44,4 +58,7 @@ const (
 var (
 	ErrLogPathNotSet        = errors.New("log path must be set")
 	ErrLogServiceNameNotSet = errors.New("log service name must be set")
+	ErrRouteNameNotSet      = errors.New("log route name must be set")
+	ErrInvalidStreamName    = errors.New("log stream name must be non-empty without path separators")
+	ErrReservedStreamName   = errors.New("log stream name is reserved by another log file")
 )
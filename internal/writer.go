@@ -8,8 +8,11 @@ import (
 	"log"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"unicode/utf8"
 )
 
 type (
@@ -20,6 +23,10 @@ type (
 		Warn(v any)
 		Error(v any)
 		Info(v any)
+		Panic(v any)
+		Fatal(v any)
+		// Log 写入自定义级别的日志
+		Log(level uint32, v any)
 		AccessRecord(v any)
 		WriteRawString(v string)
 	}
@@ -36,6 +43,8 @@ type (
 	concreteWriter struct {
 		serverLog  io.WriteCloser
 		managerLog io.WriteCloser
+		// file 表示写入服务日志文件，匹配分流规则的日志不再写入
+		file bool
 	}
 
 	emptyWriter struct{}
@@ -119,6 +128,24 @@ func (c comboWriter) Info(v any) {
 	}
 }
 
+func (c comboWriter) Panic(v any) {
+	for _, w := range c.writers {
+		w.Panic(v)
+	}
+}
+
+func (c comboWriter) Fatal(v any) {
+	for _, w := range c.writers {
+		w.Fatal(v)
+	}
+}
+
+func (c comboWriter) Log(level uint32, v any) {
+	for _, w := range c.writers {
+		w.Log(level, v)
+	}
+}
+
 func (c comboWriter) AccessRecord(v any) {
 	for _, w := range c.writers {
 		w.AccessRecord(v)
@@ -154,32 +181,48 @@ func newFileWriter(c LogConf) (Writer, error) {
 	if c.KeepDays > 0 {
 		opts = append(opts, WithKeepDays(c.KeepDays))
 	}
+	if c.MaxAge > 0 {
+		opts = append(opts, WithMaxAge(c.MaxAge))
+	}
 	if c.MaxBackups > 0 {
 		opts = append(opts, WithMaxBackups(c.MaxBackups))
 	}
 	if c.MaxSize > 0 {
 		opts = append(opts, WithMaxSize(c.MaxSize))
 	}
+	if c.MaxTotalSize > 0 {
+		opts = append(opts, WithMaxTotalSize(c.MaxTotalSize))
+	}
+	if c.DiskThreshold > 0 {
+		opts = append(opts, WithDiskThreshold(c.DiskThreshold))
+	}
 
 	opts = append(opts, WithRotation(c.Rotation))
+	opts = append(opts, WithFallbackOutput(c.FallbackDir, c.FallbackStderr, c.FallbackBufferSize))
+	opts = append(opts, WithCompressConcurrency(c.CompressConcurrency))
+	opts = append(opts, WithCompressRateLimit(c.CompressRateLimit))
 
 	managerFile := path.Join(c.ManagerLogDir, c.ServiceName+"_"+managerFilename)
 	serverFile := path.Join(c.ServerLogDir, c.ServiceName+"_"+serverFilename)
 
 	handleOptions(opts)
 	setupLogLevel(c)
+	compressor.setConcurrency(options.compressConcurrency)
+	compressor.setRateLimit(options.compressRateLimit)
+	SetRetentionDryRun(c.RetentionDryRun)
 
-	if serverLog, err = createOutput(serverFile); err != nil {
+	if serverLog, err = createOutput(serverFile, c.Server.options()...); err != nil {
 		return nil, err
 	}
 
-	if managerLog, err = createOutput(managerFile); err != nil {
+	if managerLog, err = createOutput(managerFile, c.Manager.options()...); err != nil {
 		return nil, err
 	}
 
 	return &concreteWriter{
 		serverLog:  serverLog,
 		managerLog: managerLog,
+		file:       true,
 	}, nil
 }
 
@@ -216,6 +259,18 @@ func (w *concreteWriter) Info(v any) {
 	output(w.serverLog, LevelInfo, v)
 }
 
+func (w *concreteWriter) Panic(v any) {
+	output(w.serverLog, LevelPanic, v)
+}
+
+func (w *concreteWriter) Fatal(v any) {
+	output(w.serverLog, LevelFatal, v)
+}
+
+func (w *concreteWriter) Log(level uint32, v any) {
+	output(w.serverLog, levelTag(level), v)
+}
+
 func (w *concreteWriter) AccessRecord(v any) {
 	output(w.managerLog, levelAccessRecord, v)
 }
@@ -232,18 +287,115 @@ func (w *concreteWriter) WriteRawString(v string) {
 	}
 }
 
-func output(writer io.Writer, level string, val any) {
-	// only truncate string content, don't know how to truncate the values of other types.
-	if v, ok := val.(string); ok {
-		maxLen := atomic.LoadUint32(&maxContentLength)
-		if maxLen > 0 && len(v) > int(maxLen) {
-			val = v[:maxLen]
-		}
+// writeLevel 按日志级别调用写入器对应的方法
+func writeLevel(w Writer, level uint32, v any) {
+	switch level {
+	case TraceLevel:
+		w.Trace(v)
+	case DebugLevel:
+		w.Debug(v)
+	case InfoLevel:
+		w.Info(v)
+	case WarnLevel:
+		w.Warn(v)
+	case PanicLevel:
+		w.Panic(v)
+	case FatalLevel:
+		w.Fatal(v)
+	case ErrorLevel:
+		w.Error(v)
+	default:
+		w.Log(level, v)
 	}
+}
+
+// levelTag 返回日志级别对应的标签
+func levelTag(level uint32) string {
+	switch level {
+	case TraceLevel:
+		return LevelTrace
+	case DebugLevel:
+		return LevelDebug
+	case InfoLevel:
+		return LevelInfo
+	case WarnLevel:
+		return LevelWarn
+	case PanicLevel:
+		return LevelPanic
+	case FatalLevel:
+		return LevelFatal
+	case ErrorLevel:
+		return LevelError
+	case DisableLevel:
+		return LevelDisable
+	default:
+		tag, _ := customLevelTag(level)
+		return tag
+	}
+}
+
+// rawLevel 解析已格式化日志行首的 `[TAG]` 级别标签，如子进程日志和链路缓存的日志
+func rawLevel(v string) (uint32, bool) {
+	if !strings.HasPrefix(v, "[") {
+		return 0, false
+	}
+	end := strings.IndexByte(v, ']')
+	if end < 0 {
+		return 0, false
+	}
+
+	return parseLevel(v[1:end])
+}
+
+func output(writer io.Writer, level string, val any) {
 	writePlainAny(writer, level, val)
 }
 
+// truncate 将超过 maxContentLength 的内容在字符边界截断，并追加截断的字节数
+func truncate(s string) string {
+	maxLen := int(atomic.LoadUint32(&maxContentLength))
+	if maxLen <= 0 || len(s) <= maxLen {
+		return s
+	}
+
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(truncated %d bytes)", s[:cut], len(s)-cut)
+}
+
+// escapeMessage 转义调用方消息中的控制字符，并截断超过 maxContentLength 的部分
+// 在追加错误原因链和调用栈之前调用，追加的内容不会被截断
+func escapeMessage(msg string) string {
+	return truncate(sanitize(msg))
+}
+
+// GetOutputStringFormatted 返回格式化后的一行日志，转义调用方消息中的控制字符，用于子进程等不经过 Logger 的日志
 func GetOutputStringFormatted(level string, val any) string {
+	switch v := val.(type) {
+	case string:
+		return formatOutput(level, escapeMessage(v))
+	case error:
+		return formatOutput(level, escapeMessage(v.Error()))
+	case fmt.Stringer:
+		return formatOutput(level, escapeMessage(v.String()))
+	default:
+		return formatOutput(level, v)
+	}
+}
+
+// GetOutputStringWithDetail 与 GetOutputStringFormatted 相同，在转义后的消息之后另起一行原样追加 detail
+func GetOutputStringWithDetail(level, msg, detail string) string {
+	if len(detail) == 0 {
+		return GetOutputStringFormatted(level, msg)
+	}
+
+	return formatOutput(level, escapeMessage(msg)+"\n"+detail)
+}
+
+// formatOutput 返回格式化后的一行日志，消息已经在写入 Logger 时转义和截断
+func formatOutput(level string, val any) string {
 	switch v := val.(type) {
 	case string:
 		text := formatPlainText(level, v)
@@ -313,13 +465,84 @@ func formatPlainValue(level string, val any) bytes.Buffer {
 	buf.WriteString(getTimestamp())
 	buf.WriteByte(plainEncodingSep)
 
-	// 兜底用json表示对象的字符串
-	_ = json.NewEncoder(&buf).Encode(val)
+	// 兜底用json表示对象的字符串，超过长度时截断其中的字符串值，保证仍然是合法的json
+	var encoded bytes.Buffer
+	_ = json.NewEncoder(&encoded).Encode(val)
+	buf.Write(truncateJSON(bytes.TrimSuffix(encoded.Bytes(), []byte("\n"))))
 
 	buf.WriteByte('\n')
 	return buf
 }
 
+// truncateJSON 将 json 中超过 maxContentLength 的字符串值截断，键、结构和顺序不变
+// 在转义前的字符串上截断，不会截断在转义序列中间
+func truncateJSON(data []byte) []byte {
+	maxLen := int(atomic.LoadUint32(&maxContentLength))
+	if maxLen <= 0 || len(data) <= maxLen {
+		return data
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	// containers 记录每一层是否为对象以及已经写入的键和值的个数
+	type container struct {
+		object bool
+		count  int
+	}
+	var containers []container
+	var buf bytes.Buffer
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return data
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			containers = containers[:len(containers)-1]
+			buf.WriteByte(byte(delim))
+			continue
+		}
+
+		var isKey bool
+		if n := len(containers); n > 0 {
+			top := &containers[n-1]
+			if top.count > 0 {
+				if top.object && top.count%2 == 1 {
+					buf.WriteByte(':')
+				} else {
+					buf.WriteByte(',')
+				}
+			}
+			isKey = top.object && top.count%2 == 0
+			top.count++
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			buf.WriteByte(byte(v))
+			containers = append(containers, container{object: v == '{'})
+		case string:
+			if !isKey {
+				v = truncate(v)
+			}
+			encoded, _ := json.Marshal(v)
+			buf.Write(encoded)
+		case json.Number:
+			buf.WriteString(v.String())
+		case bool:
+			buf.WriteString(strconv.FormatBool(v))
+		case nil:
+			buf.WriteString("null")
+		}
+	}
+
+	return buf.Bytes()
+}
+
 func writePlainValue(writer io.Writer, level string, val any) {
 	buf := formatPlainValue(level, val)
 
@@ -343,5 +566,8 @@ func (w *emptyWriter) Debug(v any)             {}
 func (w *emptyWriter) Warn(v any)              {}
 func (w *emptyWriter) Error(v any)             {}
 func (w *emptyWriter) Info(v any)              {}
+func (w *emptyWriter) Panic(v any)             {}
+func (w *emptyWriter) Fatal(v any)             {}
+func (w *emptyWriter) Log(level uint32, v any) {}
 func (w *emptyWriter) AccessRecord(v any)      {}
 func (w *emptyWriter) WriteRawString(v string) {}
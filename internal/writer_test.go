@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	old := atomic.SwapUint32(&maxContentLength, 8)
+	defer atomic.StoreUint32(&maxContentLength, old)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"short", "short"},
+		{"exactly8", "exactly8"},
+		{"0123456789", "01234567...(truncated 2 bytes)"},
+		// 第三个汉字跨过第8个字节，截断到字符边界
+		{"日志截断测试", "日志...(truncated 12 bytes)"},
+	}
+	for _, test := range tests {
+		if got := truncate(test.input); got != test.want {
+			t.Errorf("truncate(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+
+	// 截断 JSON 中的字符串值，结构、键的顺序和短的值不变
+	text := GetOutputStringFormatted(LevelInfo, struct {
+		Name  string
+		Items []any
+		Ctrl  string
+	}{"long value", []any{1.5, "0123456789", true, nil}, "\x01\x01\x01\x01\x01\x01\x01\x01\x01"})
+	encoded := text[strings.IndexByte(text, '{') : len(text)-1]
+	if !json.Valid([]byte(encoded)) {
+		t.Errorf("截断后应该仍然是合法的 JSON, 得到 %q", encoded)
+	}
+	want := `{"Name":"long val...(truncated 2 bytes)","Items":[1.5,"01234567...(truncated 2 bytes)",true,null],` +
+		`"Ctrl":"\u0001\u0001\u0001\u0001\u0001\u0001\u0001\u0001...(truncated 1 bytes)"}`
+	if encoded != want {
+		t.Errorf("JSON 编码的字符串值应被截断,\n得到 %s\n期望 %s", encoded, want)
+	}
+
+	if fields := formatFields(map[string]any{"body": "0123456789"}); fields != " body=01234567...(truncated 2 bytes)" {
+		t.Errorf("字段值应被截断, 得到 %q", fields)
+	}
+}
@@ -10,7 +10,7 @@ type ALogger interface {
 }
 
 // RLogger 定义了常用的日志级别接口
-// 包含Debug、Info、Warn、Error四个日志级别
+// 包含Trace、Debug、Info、Warn、Error、Panic、Fatal日志级别
 // 支持链式调用设置traceId
 type RLogger interface {
 	// Trace 打印追踪级别日志
@@ -35,6 +35,20 @@ type RLogger interface {
 	// Errorf 打印格式化的错误级别日志
 	Errorf(format string, args ...interface{})
 
+	// Panic 打印PNC级别日志，刷新日志文件后panic
+	Panic(args ...interface{})
+	// Panicf 打印格式化的PNC级别日志，刷新日志文件后panic
+	Panicf(format string, args ...interface{})
+	// Fatal 打印FTL级别日志，刷新日志文件后退出进程
+	Fatal(args ...interface{})
+	// Fatalf 打印格式化的FTL级别日志，刷新日志文件后退出进程
+	Fatalf(format string, args ...interface{})
+
+	// Log 打印指定级别的日志，用于通过 RegisterLevel 注册的自定义级别
+	Log(level uint32, args ...interface{})
+	// Logf 打印指定级别的格式化日志，用于通过 RegisterLevel 注册的自定义级别
+	Logf(level uint32, format string, args ...interface{})
+
 	// WithTraceId 设置日志追踪ID
 	// 返回设置了traceId的新logger实例，支持链式调用
 	WithTraceId(traceId string) RLogger
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/FortuneW/qlog/internal"
+	"github.com/fatih/color"
 )
 
 // levelMap 用于日志级别字符串映射
@@ -17,6 +18,8 @@ var levelMap = map[string]uint32{
 	internal.LevelInfo:    internal.InfoLevel,
 	internal.LevelWarn:    internal.WarnLevel,
 	internal.LevelError:   internal.ErrorLevel,
+	internal.LevelPanic:   internal.PanicLevel,
+	internal.LevelFatal:   internal.FatalLevel,
 	internal.LevelDisable: internal.DisableLevel,
 }
 
@@ -27,9 +30,14 @@ var levelStrMap = map[uint32]string{
 	internal.InfoLevel:    internal.LevelInfo,
 	internal.WarnLevel:    internal.LevelWarn,
 	internal.ErrorLevel:   internal.LevelError,
+	internal.PanicLevel:   internal.LevelPanic,
+	internal.FatalLevel:   internal.LevelFatal,
 	internal.DisableLevel: internal.LevelDisable,
 }
 
+// levelLock 保护 levelMap 和 levelStrMap，注册自定义级别时会修改
+var levelLock sync.RWMutex
+
 var (
 	defaultLogLevel = "TRA"       // 保存默认日志级别
 	openTimer       *time.Timer   // 定时器
@@ -50,11 +58,67 @@ func InitWithConfig(config Config) error {
 		ManagerLogDir: config.ManagerLogDir,
 		MaxBackups:    config.MaxBackups,
 		MaxSize:       config.MaxSize,
+		KeepDays:      config.KeepDays,
+		MaxAge:        config.MaxAge,
+		MaxTotalSize:  config.MaxTotalSize,
+		DiskThreshold: config.DiskThreshold,
 		Level:         config.Level,
 		Compress:      config.Compress,
 		Rotation:      config.Rotation,
 		Mode:          config.Mode,
 		ColorConsole:  config.ColorConsole,
+
+		RetentionDryRun: config.RetentionDryRun,
+		HandleSignals:   config.HandleSignals,
+
+		FallbackDir:        config.FallbackDir,
+		FallbackStderr:     config.FallbackStderr,
+		FallbackBufferSize: config.FallbackBufferSize,
+
+		CompressConcurrency: config.CompressConcurrency,
+		CompressRateLimit:   config.CompressRateLimit,
+
+		DiskHighWatermark: config.DiskHighWatermark,
+		DiskLowWatermark:  config.DiskLowWatermark,
+		DiskPressureLevel: config.DiskPressureLevel,
+
+		RecentRecords: config.RecentRecords,
+		RecentBytes:   config.RecentBytes,
+		RecentLevel:   config.RecentLevel,
+
+		TraceBufferRecords: config.TraceBufferRecords,
+		TraceBufferTraces:  config.TraceBufferTraces,
+		TraceBufferTimeout: config.TraceBufferTimeout,
+
+		StackLevel: config.StackLevel,
+		CrashLog:   config.CrashLog,
+	}
+
+	if len(config.Streams) > 0 {
+		internalConfig.Streams = make(map[string]internal.StreamConf, len(config.Streams))
+		for name, stream := range config.Streams {
+			internalConfig.Streams[name] = stream.toInternal()
+		}
+	}
+	internalConfig.StreamDir = config.StreamDir
+	internalConfig.Server = config.Server.toInternal()
+	internalConfig.Manager = config.Manager.toInternal()
+	internalConfig.MaxContentLength = config.MaxContentLength
+	internalConfig.Sanitize = config.Sanitize
+	internalConfig.StripAnsi = config.StripAnsi
+	internalConfig.Redact = config.Redact
+	internalConfig.RedactPatterns = config.RedactPatterns
+	internalConfig.RedactFields = config.RedactFields
+	internalConfig.ErrorLog = config.ErrorLog
+	internalConfig.ErrorStream = config.ErrorLogStream.toInternal()
+
+	for _, route := range config.Routes {
+		internalConfig.Routes = append(internalConfig.Routes, internal.RouteConf{
+			Name:       route.Name,
+			Modules:    route.Modules,
+			Levels:     route.Levels,
+			StreamConf: route.StreamConfig.toInternal(),
+		})
 	}
 
 	defaultLogLevel = config.Level
@@ -62,7 +126,11 @@ func InitWithConfig(config Config) error {
 	defer func() {
 		// 文件模式下也希望输出到控制台
 		if config.ToConsole && config.Mode == modeFile {
-			internal.AddWriter(internal.NewWriter(os.Stdout))
+			if level, ok := internal.ParseLevel(config.ConsoleLevel); ok {
+				internal.AddWriterWithLevel(internal.NewWriter(os.Stdout), level)
+			} else {
+				internal.AddWriter(internal.NewWriter(os.Stdout))
+			}
 		}
 	}()
 	return internal.SetUp(internalConfig)
@@ -72,8 +140,51 @@ func UnInit() {
 	_ = internal.Close()
 }
 
+// Rotate 立即轮转所有日志文件
+func Rotate() error {
+	return internal.RotateAll()
+}
+
+// Reopen 不重命名，重新打开所有日志文件路径
+// 配合外部 logrotate 使用：移动或截断(copytruncate)文件后调用，或开启 HandleSignals 后发送 SIGHUP
+func Reopen() error {
+	return internal.ReopenAll()
+}
+
+// FinishTrace 标记链路正常结束，丢弃该链路缓存的低级别日志
+// 仅在配置了 TraceBufferRecords 时生效，链路中出现 ERR 时缓存的日志已经写入文件
+func FinishTrace(traceId string) {
+	internal.FinishTrace(traceId)
+}
+
+// WithStack 返回一个标记参数，传给 Error/Errorf 时为本条日志附加调用栈和错误的 Unwrap 原因链
+// 使用示例：log.Errorf("save failed: %v", err, qlog.WithStack())
+func WithStack() any {
+	return internal.WithStack()
+}
+
+// RegisterLevel 注册自定义日志级别，返回级别值，用于 RLogger.Log/Logf
+// tag 为三个大写字母，priority 决定级别高低，内置级别的优先级为 TRA=0、DEB=100、INF=200、WAR=300、ERR=400、PNC=500、FTL=600，
+// 例如在 INF 和 WAR 之间注册 NOTICE：qlog.RegisterLevel("NTC", 250, color.FgBlue)
+// 注册后 CheckLogLevelStr、SetLogLevelStr、GetLogLevelStr 和各个输出都可以使用该级别
+func RegisterLevel(tag string, priority uint32, colors ...color.Attribute) (uint32, error) {
+	level, err := internal.RegisterLevel(tag, priority, colors...)
+	if err != nil {
+		return 0, err
+	}
+
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	levelMap[tag] = level
+	levelStrMap[level] = tag
+	return level, nil
+}
+
 // CheckLogLevelStr 检查日志级别字符串是否有效
 func CheckLogLevelStr(level string) error {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+
 	upperLevel := strings.ToUpper(level)
 	if _, ok := levelMap[upperLevel]; !ok {
 		return fmt.Errorf("invalid log level: %s", level)
@@ -83,6 +194,9 @@ func CheckLogLevelStr(level string) error {
 
 // GetLogLevelStr 获取日志级别对应的字符串
 func GetLogLevelStr() string {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+
 	if level, ok := levelStrMap[internal.GetLevel()]; ok {
 		return level
 	} else {
@@ -92,7 +206,11 @@ func GetLogLevelStr() string {
 
 // SetLogLevelStr 设置日志级别
 func SetLogLevelStr(level string) {
-	if logLevel, ok := levelMap[strings.ToUpper(level)]; ok {
+	levelLock.RLock()
+	logLevel, ok := levelMap[strings.ToUpper(level)]
+	levelLock.RUnlock()
+
+	if ok {
 		internal.SetLevel(logLevel)
 		mlog.Infof("set log level to %s", level)
 	} else {
@@ -161,6 +279,13 @@ func GetALog() ALogger {
 	return alog
 }
 
+// GetStream 获取命名日志流实例，如审计、慢查询日志
+// 写入 StreamDir 目录下的 <ServiceName>_<name>.log，只包含时间不包含日志级别，
+// 轮转和保留使用 Streams 中同名的配置，未配置的项使用全局配置
+func GetStream(name string) ALogger {
+	return internal.GetStream(name)
+}
+
 // GetELog 获取子进程日志实例，子进程使用
 func GetELog(moduleName string) ELogger {
 	return &eLogger{moduleName: moduleName}
@@ -0,0 +1,30 @@
+package qlog
+
+import "github.com/FortuneW/qlog/internal"
+
+// RetentionItem 描述一个将被保留策略删除的归档文件
+type RetentionItem = internal.RetentionItem
+
+// 保留策略删除文件的原因
+const (
+	RetentionReasonCount = internal.RetentionReasonCount // 超过最大备份数量
+	RetentionReasonSize  = internal.RetentionReasonSize  // 超过日志流总大小限制
+	RetentionReasonAge   = internal.RetentionReasonAge   // 超过保留时长
+	RetentionReasonDisk  = internal.RetentionReasonDisk  // 磁盘压力
+	RetentionReasonRule  = internal.RetentionReasonRule  // 自定义轮转规则
+)
+
+// PlanRetention 返回所有日志文件当前会被保留策略删除的文件及原因，不做任何删除
+func PlanRetention() []RetentionItem {
+	return internal.PlanRetention()
+}
+
+// SetRetentionDryRun 开启或关闭保留策略演练模式，开启后只打印删除决策而不真正删除文件
+func SetRetentionDryRun(enabled bool) {
+	internal.SetRetentionDryRun(enabled)
+}
+
+// OnRetentionDelete 设置文件被保留策略实际删除后的回调，每删除一个文件回调一次
+func OnRetentionDelete(fn func(item RetentionItem)) {
+	internal.SetRetentionHandler(fn)
+}
@@ -0,0 +1,48 @@
+package qlog
+
+import (
+	"io"
+
+	"github.com/FortuneW/qlog/internal"
+)
+
+type (
+	// RingRecord 是内存中保留的一条最近日志
+	RingRecord = internal.RingRecord
+	// RingWriter 在内存中保留最近的日志，实现了 internal.Writer
+	RingWriter = internal.RingWriter
+)
+
+// NewRingWriter 返回一个保留最近 maxRecords 条或 maxBytes 字节日志的 RingWriter
+func NewRingWriter(maxRecords, maxBytes int) *RingWriter {
+	return internal.NewRingWriter(maxRecords, maxBytes)
+}
+
+// RecentLogs 返回内存中保留的最近日志，未配置 RecentRecords/RecentBytes 时返回空
+func RecentLogs() []RingRecord {
+	ring := internal.RecentRing()
+	if ring == nil {
+		return nil
+	}
+	return ring.Snapshot()
+}
+
+// StreamRecentLogs 订阅之后写入的最近日志，返回取消订阅的函数
+// 回调在写日志的协程中同步执行，不要在回调中阻塞
+func StreamRecentLogs(fn func(record RingRecord)) (cancel func()) {
+	ring := internal.RecentRing()
+	if ring == nil {
+		return func() {}
+	}
+	return ring.Stream(fn)
+}
+
+// DumpRecentLogs 将内存中保留的最近日志写入 w，可用于管理接口或者崩溃时导出
+func DumpRecentLogs(w io.Writer) error {
+	ring := internal.RecentRing()
+	if ring == nil {
+		return nil
+	}
+	_, err := ring.WriteTo(w)
+	return err
+}
@@ -47,6 +47,30 @@ func (r rLogger) Errorf(format string, args ...interface{}) {
 	r.rlog.Errorf(format, args...)
 }
 
+func (r rLogger) Panic(args ...interface{}) {
+	r.rlog.Panic(args...)
+}
+
+func (r rLogger) Panicf(format string, args ...interface{}) {
+	r.rlog.Panicf(format, args...)
+}
+
+func (r rLogger) Fatal(args ...interface{}) {
+	r.rlog.Fatal(args...)
+}
+
+func (r rLogger) Fatalf(format string, args ...interface{}) {
+	r.rlog.Fatalf(format, args...)
+}
+
+func (r rLogger) Log(level uint32, args ...interface{}) {
+	r.rlog.Log(level, args...)
+}
+
+func (r rLogger) Logf(level uint32, format string, args ...interface{}) {
+	r.rlog.Logf(level, format, args...)
+}
+
 func (r rLogger) WithTraceId(traceId string) RLogger {
 	return &rLogger{rlog: r.rlog.WithTraceId(traceId)}
 }
@@ -58,7 +82,7 @@ func WriteELogItem(item *ELogItem) {
 	if item == nil {
 		return
 	}
-	if internal.GetLevel() > item.Level {
+	if !internal.ShallLog(item.Level) {
 		return
 	}
 	elog.WriteRawString(item.Content)